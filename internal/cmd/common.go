@@ -23,120 +23,180 @@ package cmd
 import (
 	"context"
 	"errors"
+	"os"
+	"regexp"
 	"strings"
-	"sync"
 
 	"github.com/PagerDuty/go-pagerduty"
 	"github.com/gonvenience/wrap"
 	"github.com/homeport/pd/internal/pd"
 )
 
-func getRelevantIncidents(ctx context.Context, userID string, from string, to string) ([]pagerduty.Incident, string, error) {
-	client, err := pd.CreatePagerDutyClient()
-	if err != nil {
-		return nil, "", err
+// newClient creates the PagerDuty client used by all commands, it can be
+// replaced to run the commands against a pd.FakeClient
+var newClient = pd.CreatePagerDutyClient
+
+// errNoTeams is returned if the incidents of the teams of a user are
+// requested, but the user is not part of any team
+var errNoTeams = errors.New("this PagerDuty-account is not part of any teams. To use this function, the PagerDuty-account must be part of at least one team")
+
+// incidentFilter selects the incidents returned by getRelevantIncidents,
+// statuses, urgencies, services, and teams are passed on to the PagerDuty
+// API, the other criteria are checked locally, allDates lifts the default
+// time range of the API if no from and to are given, without involvements
+// the incidents are not filtered by the involvement of the user
+type incidentFilter struct {
+	from                string
+	to                  string
+	allDates            bool
+	involvements        []pd.Involvement
+	statuses            []string
+	urgencies           []string
+	serviceIDs          []string
+	teamIDs             []string
+	escalationPolicyIDs []string
+	priorities          []string
+	match               *regexp.Regexp
+}
+
+// matches checks the criteria that the PagerDuty API cannot filter by
+func (f incidentFilter) matches(incident pagerduty.Incident) bool {
+	if len(f.escalationPolicyIDs) > 0 && !contains(f.escalationPolicyIDs, incident.EscalationPolicy.ID) {
+		return false
+	}
+
+	if len(f.priorities) > 0 {
+		if incident.Priority == nil {
+			return false
+		}
+
+		var found bool
+		for _, priority := range f.priorities {
+			if priority == incident.Priority.ID || strings.EqualFold(priority, incident.Priority.Name) || strings.EqualFold(priority, incident.Priority.Summary) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	if f.match != nil && !f.match.MatchString(incident.Title) && !f.match.MatchString(incident.Description) {
+		return false
 	}
 
-	var user *pagerduty.User
+	return true
+}
+
+// getRelevantIncidents returns the incidents of the teams of the user (or of
+// the current user if no ID is given) that match the filter and in which the
+// user was involved in one of the ways of the filter according to the
+// incident log entries
+func getRelevantIncidents(ctx context.Context, client pd.Client, userID string, filter incidentFilter) ([]pagerduty.Incident, string, error) {
+	var (
+		user *pagerduty.User
+		err  error
+	)
+
 	if userID == "" {
 		user, err = client.GetCurrentUserWithContext(ctx, pagerduty.GetCurrentUserOptions{})
 		if err != nil {
-			return nil, user.Name, wrap.Error(err, "it seems like the authtoken is not set correctly or outdated. Please update the authtoken in the .pd.yml file. If you don't know how to create your authtoken, this might help:\n https://support.pagerduty.com/docs/generating-api-keys#generating-a-personal-rest-api-key\n")
+			return nil, "", wrap.Error(err, "it seems like the authtoken is not set correctly or outdated. Please update the authtoken in the .pd.yml file. If you don't know how to create your authtoken, this might help:\n https://support.pagerduty.com/docs/generating-api-keys#generating-a-personal-rest-api-key\n")
 		}
 	} else {
 		user, err = client.GetUserWithContext(ctx, userID, pagerduty.GetUserOptions{})
 		if err != nil {
-			return nil, user.Name, wrap.Error(err, "it seems like the authtoken is not set correctly/outdated or the user-ID is invalid. Please update the authtoken in the .pd.yml file or use another user-ID. If you don't know how to create your authtoken, this might help:\n https://support.pagerduty.com/docs/generating-api-keys#generating-a-personal-rest-api-key\n")
+			return nil, "", wrap.Error(err, "it seems like the authtoken is not set correctly/outdated or the user-ID is invalid. Please update the authtoken in the .pd.yml file or use another user-ID. If you don't know how to create your authtoken, this might help:\n https://support.pagerduty.com/docs/generating-api-keys#generating-a-personal-rest-api-key\n")
 		}
 	}
 
-	incidents := []pagerduty.Incident{}
-	offset := 0
-	limit := 100
-	teamIDs := listTeamIDs(*user)
-	moreIncidents := len(teamIDs) > 0
-	if !moreIncidents {
-		return nil, user.Name, errors.New("this PagerDuty-account is not part of any teams. To use this function, the PagerDuty-account must be part of at least one team")
-	}
-	for moreIncidents {
-		listIncidentsOptions := pagerduty.ListIncidentsOptions{
-			Limit:   uint(limit),
-			Offset:  uint(offset),
-			Since:   from,
-			Until:   to,
-			TeamIDs: teamIDs,
-		}
-		a, err := client.ListIncidentsWithContext(ctx, listIncidentsOptions)
-		if err != nil {
-			return incidents, user.Name, err
-		}
-		incs, err := filterIncidentsByNameInLogEntries(ctx, a.Incidents, user.Name, client)
-		if err != nil {
-			return incidents, user.Name, err
-		}
-		incidents = append(incidents, incs...)
+	teamIDs := filter.teamIDs
+	if len(teamIDs) == 0 {
+		teamIDs = listTeamIDs(*user)
+	}
 
-		if a.More {
-			offset += limit
-		} else {
-			moreIncidents = false
-		}
+	if len(teamIDs) == 0 {
+		return nil, user.Name, errNoTeams
 	}
 
-	return incidents, user.Name, nil
-}
+	var dateRange string
+	if filter.allDates {
+		dateRange = "all"
+	}
 
-func filterIncidentsByNameInLogEntries(ctx context.Context, incidents []pagerduty.Incident, username string, client *pagerduty.Client) ([]pagerduty.Incident, error) {
-	const parallel = 10
+	list, err := pd.ListAllIncidents(ctx, client, pagerduty.ListIncidentsOptions{
+		Since:      filter.from,
+		Until:      filter.to,
+		DateRange:  dateRange,
+		TeamIDs:    teamIDs,
+		Statuses:   filter.statuses,
+		Urgencies:  filter.urgencies,
+		ServiceIDs: filter.serviceIDs,
+	})
+	if err != nil {
+		return nil, user.Name, err
+	}
 
-	type in struct {
-		index    int
-		incident pagerduty.Incident
+	var candidates []pagerduty.Incident
+	for _, incident := range list {
+		if filter.matches(incident) {
+			candidates = append(candidates, incident)
+		}
 	}
 
-	var (
-		errors     = []error{}
-		tasks      = make(chan in, len(incidents))
-		logEntries = make([]pagerduty.ListIncidentLogEntriesResponse, len(incidents))
-	)
+	if len(filter.involvements) == 0 {
+		return candidates, user.Name, nil
+	}
 
-	// Fill the task channel with work to be done
-	for idx, incident := range incidents {
-		tasks <- in{idx, incident}
-	}
-
-	// Start a worker group to process work tasks
-	var wg sync.WaitGroup
-	wg.Add(parallel)
-	for i := 0; i < parallel; i++ {
-		go func() {
-			defer wg.Done()
-			for task := range tasks {
-				resp, err := client.ListIncidentLogEntriesWithContext(ctx, task.incident.ID, pagerduty.ListIncidentLogEntriesOptions{})
-				if err != nil {
-					errors = append(errors, err)
-				}
-				logEntries[task.index] = *resp
-			}
-		}()
+	incidents, err := filterIncidentsByInvolvement(ctx, client, candidates, user.ID, filter.involvements)
+	if err != nil {
+		return nil, user.Name, err
+	}
+
+	return incidents, user.Name, nil
+}
+
+func filterIncidentsByInvolvement(ctx context.Context, client pd.Client, incidents []pagerduty.Incident, userID string, involvements []pd.Involvement) ([]pagerduty.Incident, error) {
+	logEntries, errs := pd.ParallelMap(ctx, pd.DefaultParallelism, incidents, func(ctx context.Context, incident pagerduty.Incident) ([]pagerduty.LogEntry, error) {
+		return pd.ListAllIncidentLogEntries(ctx, client, incident.ID, pagerduty.ListIncidentLogEntriesOptions{})
+	})
+
+	// without the log entries of all incidents, any result would be incomplete
+	if len(errs) > 0 {
+		return nil, wrap.Errors(errs, "failed to filter incidents by involvement")
 	}
-	close(tasks)
-	wg.Wait()
 
 	var filteredIncidents []pagerduty.Incident
 	for i, incident := range incidents {
-		for _, logEntry := range logEntries[i].LogEntries {
-			if strings.Contains(logEntry.CommonLogEntryField.Summary, username) {
-				filteredIncidents = append(filteredIncidents, incident)
-				break
-			}
+		if pd.IsInvolved(logEntries[i], userID, involvements) {
+			filteredIncidents = append(filteredIncidents, incident)
 		}
 	}
-	if len(errors) > 0 {
-		return filteredIncidents, wrap.Errors(errors, "failed to filter incidents by name")
-	}
+
 	return filteredIncidents, nil
+}
+
+// isTerminal returns whether the reader or writer is an interactive terminal
+func isTerminal(v interface{}) bool {
+	file, ok := v.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func contains(list []string, value string) bool {
+	for _, entry := range list {
+		if entry == value {
+			return true
+		}
+	}
 
+	return false
 }
 
 func listTeamIDs(user pagerduty.User) []string {
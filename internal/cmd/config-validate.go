@@ -0,0 +1,97 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/gonvenience/bunt"
+	"github.com/homeport/pd/internal/pd"
+	"github.com/spf13/cobra"
+)
+
+// configValidateCmd represents the config validate command
+var configValidateCmd = &cobra.Command{
+	Use:          "validate",
+	Args:         cobra.ExactArgs(0),
+	Short:        "Validate the shift configuration",
+	Long:         `Checks the shifts in the .pd.yml file for malformed entries, duplicate names, overlaps, and gaps`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+
+		issues, err := pd.ValidateShifts()
+		if err != nil {
+			return err
+		}
+
+		if isStructuredOutput() {
+			result := []shiftIssueOutput{}
+			for _, issue := range issues {
+				severity := "error"
+				if issue.Warning {
+					severity = "warning"
+				}
+
+				result = append(result, shiftIssueOutput{Line: issue.Line, Severity: severity, Message: issue.Message})
+			}
+
+			if err := printStructured(out, result); err != nil {
+				return err
+			}
+
+			for _, issue := range issues {
+				if !issue.Warning {
+					return fmt.Errorf("the shift configuration contains errors")
+				}
+			}
+
+			return nil
+		}
+
+		if len(issues) == 0 {
+			bunt.Fprintf(out, "\nThe shifts in the .pd.yml file are *configured correctly*.\n\n")
+			return nil
+		}
+
+		var errors int
+		bunt.Fprintln(out)
+		for _, issue := range issues {
+			if issue.Warning {
+				bunt.Fprintf(out, "Gold{warning:} %s\n", issue.Error())
+			} else {
+				bunt.Fprintf(out, "FireBrick{error:} %s\n", issue.Error())
+				errors++
+			}
+		}
+		bunt.Fprintln(out)
+
+		if errors > 0 {
+			return fmt.Errorf("the shift configuration contains %d error(s)", errors)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+}
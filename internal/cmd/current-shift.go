@@ -21,6 +21,9 @@
 package cmd
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/gonvenience/bunt"
 	"github.com/homeport/pd/internal/pd"
 	"github.com/spf13/cobra"
@@ -33,45 +36,79 @@ var currentShiftCmd = &cobra.Command{
 	Short: "Display current shift",
 	Long:  `Displays the currently active shift`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
 
 		shifts, shiftPos, ownShiftPos, err := pd.GetCurrentAndOwnShift()
 		if err != nil {
 			return err
 		}
+		if isStructuredOutput() {
+			return printStructured(out, newShiftStatusOutput(shifts, shiftPos, ownShiftPos))
+		}
 		if len(shifts) == 0 || shiftPos == -1 {
-			bunt.Printf("\nThe shifts in the .pd.yml file are *not or wrongly configured*. Please configure them correctly to use this command.\n\n")
+			bunt.Fprintf(out, "\nThe shifts in the .pd.yml file are *not or wrongly configured*. Please configure them correctly to use this command.\n\n")
 			return nil
 		}
-		bunt.Printf("\nAt the moment, SkyBlue{%s} is in charge.\n", shifts[shiftPos].Name)
+		bunt.Fprintf(out, "\nAt the moment, SkyBlue{%s} is in charge.\n", shifts[shiftPos].Name)
 
-		nextShiftPos := (shiftPos + 1) % len(shifts)
-		nextShift := shifts[nextShiftPos]
-		timeUntilNextShift, err := pd.GetTimeUntilShift(shifts, shiftPos+1)
+		now := time.Now()
+		nextShift, handover, err := pd.GetNextShift(shifts, now)
 		if err != nil {
 			return err
 		}
-		bunt.Printf("The next shift will be SkyBlue{%s} in %d:%02d hours\n", nextShift.Name, timeUntilNextShift/60, timeUntilNextShift%60)
+		bunt.Fprintf(out, "The next shift will be SkyBlue{%s} in %s hours\n", nextShift.Name, formatHours(handover.Sub(now)))
 
 		if ownShiftPos == -1 {
-			bunt.Printf("\nYour region has not been set yet. In case you want to set it in the configuration, please run 'LightSlateGray{pd %s [region-name]}'\n\n", cmdName)
+			bunt.Fprintf(out, "\nYour region has not been set yet. In case you want to set it in the configuration, please run 'LightSlateGray{pd %s [region-name]}'\n\n", cmdName)
 			return nil
 		}
 
-		if ownShiftPos != shiftPos && ownShiftPos != nextShiftPos {
+		if ownShift := shifts[ownShiftPos]; ownShift.Name != shifts[shiftPos].Name && ownShift.Name != nextShift.Name {
 			timeUntilOwnShift, err := pd.GetTimeUntilShift(shifts, ownShiftPos)
 			if err != nil {
 				return err
 			}
-			ownShift := shifts[ownShiftPos]
-			bunt.Printf("SkyBlue{%s} will have the next shift in %d:%02d hours\n\n", ownShift.Name, timeUntilOwnShift/60, timeUntilOwnShift%60)
+			bunt.Fprintf(out, "SkyBlue{%s} will have the next shift in %s hours\n\n", ownShift.Name, formatHours(timeUntilOwnShift))
 		} else {
-			bunt.Println("")
+			bunt.Fprintln(out)
 		}
 
 		return nil
 	},
 }
 
+func newShiftStatusOutput(shifts []pd.Shift, shiftPos int, ownShiftPos int) shiftStatusOutput {
+	var result shiftStatusOutput
+	if len(shifts) == 0 {
+		return result
+	}
+
+	now := time.Now()
+	if shiftPos != -1 {
+		result.Current = newShiftOutput(shifts[shiftPos])
+	}
+
+	if nextShift, handover, err := pd.GetNextShift(shifts, now); err == nil {
+		result.Next = newShiftOutput(nextShift)
+		result.Handover = &handover
+	}
+
+	if ownShiftPos != -1 {
+		result.Own = newShiftOutput(shifts[ownShiftPos])
+		if ownShiftStart, err := pd.GetNextStartOfShift(shifts, ownShiftPos, now); err == nil {
+			result.OwnShiftStart = &ownShiftStart
+		}
+	}
+
+	return result
+}
+
+// formatHours returns the duration in the H:MM format
+func formatHours(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	return fmt.Sprintf("%d:%02d", minutes/60, minutes%60)
+}
+
 func init() {
 	rootCmd.AddCommand(currentShiftCmd)
 }
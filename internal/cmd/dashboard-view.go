@@ -0,0 +1,284 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/gonvenience/bunt"
+	"github.com/gonvenience/neat"
+	"github.com/homeport/pd/internal/pd"
+)
+
+// dashboardTitleWidth is the maximum number of characters of incident titles
+const dashboardTitleWidth = 60
+
+// dashboardData is everything shown on the dashboard, the errors of the
+// sections are shown instead of their content
+type dashboardData struct {
+	fetchedAt time.Time
+	user      *pagerduty.User
+
+	shifts      []pd.Shift
+	shiftPos    int
+	ownShiftPos int
+	shiftErr    error
+
+	onCalls   []pd.OnCallWindow
+	onCallErr error
+
+	incidents   []pagerduty.Incident
+	incidentErr error
+}
+
+// fetchDashboardData collects the shifts, the on-call windows of the next
+// days, and the open incidents of the teams of the user
+func fetchDashboardData(ctx context.Context, client pd.Client, user *pagerduty.User) *dashboardData {
+	data := dashboardData{fetchedAt: time.Now(), user: user}
+	data.shifts, data.shiftPos, data.ownShiftPos, data.shiftErr = pd.GetCurrentAndOwnShift()
+
+	data.onCalls, data.onCallErr = pd.GetPagerDutyOnCalls(ctx, client, user,
+		data.fetchedAt.Format(time.RFC3339),
+		data.fetchedAt.AddDate(0, 0, defaultOnCallDays).Format(time.RFC3339),
+	)
+
+	// without teams, the API would return the incidents of the whole account
+	teamIDs := listTeamIDs(*user)
+	if len(teamIDs) == 0 {
+		data.incidentErr = errNoTeams
+		return &data
+	}
+
+	// incidents can stay open for longer than the default time range of the API
+	data.incidents, data.incidentErr = pd.ListAllIncidents(ctx, client, pagerduty.ListIncidentsOptions{
+		DateRange: "all",
+		TeamIDs:   teamIDs,
+		Statuses:  []string{"triggered", "acknowledged"},
+	})
+
+	sortIncidentsByUrgency(data.incidents)
+	return &data
+}
+
+// sortIncidentsByUrgency puts high urgency before low urgency incidents,
+// triggered before acknowledged ones, and newer before older ones
+func sortIncidentsByUrgency(incidents []pagerduty.Incident) {
+	sort.SliceStable(incidents, func(i, j int) bool {
+		a, b := incidents[i], incidents[j]
+		switch {
+		case a.Urgency != b.Urgency:
+			return a.Urgency == "high"
+
+		case a.Status != b.Status:
+			return a.Status == "triggered"
+
+		default:
+			// timestamps that cannot be parsed are zero and go last
+			createdA, _ := pd.ParseTimestamp(a.CreatedAt)
+			createdB, _ := pd.ParseTimestamp(b.CreatedAt)
+			return createdA.After(createdB)
+		}
+	})
+}
+
+// renderDashboard writes the dashboard, the selected incident is marked if
+// selected is not negative, with a maximum height the incident list is
+// scrolled to keep the selected incident visible
+func renderDashboard(out io.Writer, data *dashboardData, selected int, height int, status string) error {
+	var (
+		now  = time.Now()
+		head strings.Builder
+		tail strings.Builder
+	)
+
+	bunt.Fprintf(&head, "*pd dashboard* for *%s*, refreshed at %s\n\n", data.user.Name, pd.InTimezone(data.fetchedAt).Format("15:04:05"))
+
+	bunt.Fprintf(&head, "*Shift*\n")
+	switch {
+	case data.shiftErr != nil:
+		bunt.Fprintf(&head, "  FireBrick{%v}\n", data.shiftErr)
+
+	case len(data.shifts) == 0 || data.shiftPos == -1:
+		bunt.Fprintf(&head, "  The shifts in the .pd.yml file are *not or wrongly configured*.\n")
+
+	default:
+		bunt.Fprintf(&head, "  SkyBlue{%s} is in charge", data.shifts[data.shiftPos].Name)
+		next, handover, err := pd.GetNextShift(data.shifts, now)
+		if err == nil {
+			bunt.Fprintf(&head, ", SkyBlue{%s} takes over in %s hours (at %s)", next.Name, formatHours(handover.Sub(now)), pd.InTimezone(handover).Format("15:04"))
+		}
+
+		bunt.Fprintln(&head)
+
+		if own := data.ownShiftPos; own != -1 && data.shifts[own].Name != data.shifts[data.shiftPos].Name && data.shifts[own].Name != next.Name {
+			if d, err := pd.GetTimeUntilShift(data.shifts, data.ownShiftPos); err == nil {
+				bunt.Fprintf(&head, "  Your shift SkyBlue{%s} starts in %s hours\n", data.shifts[data.ownShiftPos].Name, formatHours(d))
+			}
+		}
+	}
+
+	bunt.Fprintf(&head, "\n*On-call* in the next %d days\n", defaultOnCallDays)
+	switch {
+	case data.onCallErr != nil:
+		bunt.Fprintf(&head, "  FireBrick{%v}\n", data.onCallErr)
+
+	case len(data.onCalls) == 0:
+		bunt.Fprintf(&head, "  You are not on-call.\n")
+
+	default:
+		for _, window := range data.onCalls {
+			start := pd.InTimezone(window.Start).Format("Mon 2006-01-02 15:04")
+			if !window.Start.After(now) {
+				start = bunt.Sprint("SeaGreen{now}")
+			}
+
+			until := "until " + pd.InTimezone(window.End).Format("Mon 2006-01-02 15:04")
+			if window.End.IsZero() {
+				until = "permanently"
+			}
+
+			bunt.Fprintf(&head, "  %s %s, level %d of %s\n", start, until, window.EscalationLevel, window.EscalationPolicy.Summary)
+		}
+	}
+
+	var rows []string
+	switch {
+	case data.incidentErr != nil:
+		bunt.Fprintf(&head, "\n*Open incidents*\n")
+		bunt.Fprintf(&head, "  FireBrick{%v}\n", data.incidentErr)
+
+	case len(data.incidents) == 0:
+		bunt.Fprintf(&head, "\n*Open incidents*\n")
+		bunt.Fprintf(&head, "  There are *no* open incidents.\n")
+
+	default:
+		var triggered int
+		for _, incident := range data.incidents {
+			if incident.Status == "triggered" {
+				triggered++
+			}
+		}
+
+		bunt.Fprintf(&head, "\n*Open incidents* (FireBrick{%d triggered}, Gold{%d acknowledged})\n", triggered, len(data.incidents)-triggered)
+
+		var table [][]string
+		for i, incident := range data.incidents {
+			var marker string
+			if i == selected {
+				marker = bunt.Sprint("CornflowerBlue{▶}")
+			}
+
+			var age string
+			if createdAt, err := pd.ParseTimestamp(incident.CreatedAt); err == nil {
+				age = formatDuration(now.Sub(createdAt))
+			}
+
+			table = append(table, []string{
+				marker,
+				fmt.Sprintf("#%d", incident.IncidentNumber),
+				incidentUrgency(incident.Urgency),
+				incidentStatus(incident.Status),
+				age,
+				truncate(incident.Title, dashboardTitleWidth),
+				incident.Service.Summary,
+			})
+		}
+
+		content, err := neat.Table(table)
+		if err != nil {
+			return err
+		}
+
+		rows = strings.Split(strings.TrimRight(content, "\n"), "\n")
+	}
+
+	if selected >= 0 {
+		bunt.Fprintf(&tail, "\nDimGray{↑/↓ select, a acknowledge, r resolve, o open, space refresh, q quit}\n")
+	}
+
+	if status != "" {
+		bunt.Fprintf(&tail, "%s\n", status)
+	}
+
+	// scroll the incidents so that the selected one stays visible
+	if lines := height - strings.Count(head.String(), "\n") - strings.Count(tail.String(), "\n"); height > 0 && len(rows) > lines {
+		if lines < 1 {
+			lines = 1
+		}
+
+		offset := selected - lines + 1
+		if offset < 0 {
+			offset = 0
+		}
+
+		rows = rows[offset : offset+lines]
+	}
+
+	if _, err := io.WriteString(out, head.String()); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if _, err := fmt.Fprintf(out, "  %s\n", row); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(out, tail.String())
+	return err
+}
+
+func incidentStatus(status string) string {
+	switch status {
+	case "triggered":
+		return bunt.Sprintf("FireBrick{%s}", status)
+
+	case "acknowledged":
+		return bunt.Sprintf("Gold{%s}", status)
+
+	default:
+		return status
+	}
+}
+
+func incidentUrgency(urgency string) string {
+	if urgency == "high" {
+		return bunt.Sprintf("FireBrick{%s}", urgency)
+	}
+
+	return urgency
+}
+
+// truncate shortens the text to the given number of characters
+func truncate(text string, length int) string {
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+
+	return string([]rune(text)[:length-1]) + "…"
+}
@@ -0,0 +1,136 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+func TestSortIncidentsByUrgency(t *testing.T) {
+	incident := func(id string, urgency string, status string, createdAt string) pagerduty.Incident {
+		return pagerduty.Incident{APIObject: pagerduty.APIObject{ID: id}, Urgency: urgency, Status: status, CreatedAt: createdAt}
+	}
+
+	tests := []struct {
+		name      string
+		incidents []pagerduty.Incident
+		expected  []string
+	}{
+		{
+			name: "urgency before status",
+			incidents: []pagerduty.Incident{
+				incident("P1", "low", "triggered", "2022-11-07T08:00:00Z"),
+				incident("P2", "high", "acknowledged", "2022-11-07T08:00:00Z"),
+				incident("P3", "high", "triggered", "2022-11-07T08:00:00Z"),
+			},
+			expected: []string{"P3", "P2", "P1"},
+		},
+		{
+			name: "newer first across offsets",
+			incidents: []pagerduty.Incident{
+				incident("P1", "high", "triggered", "2022-11-07T09:30:00+02:00"),
+				incident("P2", "high", "triggered", "2022-11-07T08:00:00Z"),
+				incident("P3", "high", "triggered", "2022-11-07T07:45:00-01:00"),
+			},
+			expected: []string{"P3", "P2", "P1"},
+		},
+		{
+			name: "newer first with fractional seconds",
+			incidents: []pagerduty.Incident{
+				incident("P1", "high", "triggered", "2022-11-07T08:00:00Z"),
+				incident("P2", "high", "triggered", "2022-11-07T08:00:00.5Z"),
+				incident("P3", "high", "triggered", "broken"),
+			},
+			expected: []string{"P2", "P1", "P3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortIncidentsByUrgency(tt.incidents)
+
+			for i, id := range tt.expected {
+				if tt.incidents[i].ID != id {
+					t.Errorf("expected %s at position %d, got %s", id, i, tt.incidents[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchDashboardData(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tests := []struct {
+		name      string
+		teams     []pagerduty.Team
+		incidents []string
+		listings  int
+		err       error
+	}{
+		{
+			name:      "open incidents of the teams of the user",
+			teams:     []pagerduty.Team{{APIObject: pagerduty.APIObject{ID: "PTEAM01"}}},
+			incidents: []string{"PINC001", "PINC002"},
+			listings:  1,
+		},
+		{
+			name: "user without teams",
+			err:  errNoTeams,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &incidentListRecorder{FakeClient: newTestClient()}
+			user := *client.CurrentUser
+			user.Teams = tt.teams
+
+			data := fetchDashboardData(context.Background(), client, &user)
+			if !errors.Is(data.incidentErr, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, data.incidentErr)
+			}
+
+			if len(client.options) != tt.listings {
+				t.Errorf("expected %d incident listings, got %d", tt.listings, len(client.options))
+			}
+
+			var ids []string
+			for _, incident := range data.incidents {
+				ids = append(ids, incident.ID)
+			}
+
+			if len(ids) != len(tt.incidents) {
+				t.Fatalf("expected incidents %v, got %v", tt.incidents, ids)
+			}
+
+			for i := range ids {
+				if ids[i] != tt.incidents[i] {
+					t.Errorf("expected incidents %v, got %v", tt.incidents, ids)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,379 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/gonvenience/bunt"
+	"github.com/gonvenience/wrap"
+	"github.com/homeport/pd/internal/pd"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Terminal control sequences used by the interactive dashboard
+const (
+	enterAlternateScreen = "\033[?1049h\033[?25l"
+	leaveAlternateScreen = "\033[?25h\033[?1049l"
+	clearScreen          = "\033[H\033[2J"
+)
+
+var dashboardCmdSettings struct {
+	interval time.Duration
+}
+
+// dashboardCmd represents the dashboard command
+var dashboardCmd = &cobra.Command{
+	Use:   "dashboard",
+	Args:  cobra.NoArgs,
+	Short: "Show a dashboard of the current shift",
+	Long: `Shows the current and next shift, your on-call windows, and the open
+incidents of your teams on one screen that refreshes itself, incidents can be
+acknowledged, resolved, and opened in the browser using the keyboard, if the
+output is not a terminal, the dashboard is printed after every refresh`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var (
+			ctx = cmd.Context()
+			out = cmd.OutOrStdout()
+		)
+
+		if dashboardCmdSettings.interval < minWatchInterval {
+			return fmt.Errorf("the interval must be at least %s", minWatchInterval)
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		user, err := client.GetCurrentUserWithContext(ctx, pagerduty.GetCurrentUserOptions{})
+		if err != nil {
+			return wrap.Error(err, "it seems like the authtoken is not set correctly or outdated. Please update the authtoken in the .pd.yml file. If you don't know how to create your authtoken, this might help:\n https://support.pagerduty.com/docs/generating-api-keys#generating-a-personal-rest-api-key\n")
+		}
+
+		switch {
+		case isStructuredOutput():
+			return printDashboardSnapshot(ctx, out, client, user)
+
+		case isTerminal(os.Stdin) && isTerminal(out):
+			return runInteractiveDashboard(ctx, out, client, user)
+
+		default:
+			return runPlainDashboard(ctx, out, client, user)
+		}
+	},
+}
+
+// printDashboardSnapshot prints the content of the dashboard once
+func printDashboardSnapshot(ctx context.Context, out io.Writer, client pd.Client, user *pagerduty.User) error {
+	data := fetchDashboardData(ctx, client, user)
+	for _, err := range []error{data.shiftErr, data.onCallErr, data.incidentErr} {
+		if err != nil {
+			return err
+		}
+	}
+
+	incidents, err := newIncidentOutputs(ctx, client, data.incidents)
+	if err != nil {
+		return err
+	}
+
+	return printStructured(out, dashboardOutput{
+		Shift:     newShiftStatusOutput(data.shifts, data.shiftPos, data.ownShiftPos),
+		OnCalls:   newOnCallsOutput(data.onCalls),
+		Incidents: incidents,
+	})
+}
+
+// runPlainDashboard prints the dashboard after every refresh until the
+// context is cancelled
+func runPlainDashboard(ctx context.Context, out io.Writer, client pd.Client, user *pagerduty.User) error {
+	for {
+		data := fetchDashboardData(ctx, client, user)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		bunt.Fprintln(out)
+		if err := renderDashboard(out, data, -1, 0, ""); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-time.After(dashboardCmdSettings.interval):
+		}
+	}
+}
+
+// runInteractiveDashboard shows the dashboard full-screen and handles the
+// keyboard input until q or Ctrl-C is pressed
+func runInteractiveDashboard(ctx context.Context, out io.Writer, client pd.Client, user *pagerduty.User) error {
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return wrap.Error(err, "failed to set up the terminal")
+	}
+
+	defer term.Restore(int(os.Stdin.Fd()), state)
+
+	fmt.Fprint(out, enterAlternateScreen)
+	defer fmt.Fprint(out, leaveAlternateScreen)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	var (
+		keys      = readDashboardKeys(os.Stdin, done)
+		refresh   = time.NewTicker(dashboardCmdSettings.interval)
+		redraw    = time.NewTicker(time.Second)
+		data      = fetchDashboardData(ctx, client, user)
+		selected  int
+		status    string
+		refreshed = make(chan *dashboardData, 1)
+		fetching  bool
+		outdated  bool
+	)
+
+	defer refresh.Stop()
+	defer redraw.Stop()
+
+	// the data is fetched in the background to keep handling keys, a refresh
+	// requested while fetching is started once the running one is done
+	startRefresh := func() {
+		if fetching {
+			outdated = true
+			return
+		}
+
+		fetching = true
+		go func() { refreshed <- fetchDashboardData(ctx, client, user) }()
+	}
+
+	for {
+		if selected >= len(data.incidents) {
+			selected = len(data.incidents) - 1
+		}
+
+		if selected < 0 {
+			selected = 0
+		}
+
+		var buf bytes.Buffer
+		_, height, _ := term.GetSize(int(os.Stdin.Fd()))
+		if err := renderDashboard(&buf, data, selected, height-1, status); err != nil {
+			return err
+		}
+
+		// the raw terminal does not return to the start of the line by itself
+		fmt.Fprint(out, clearScreen+strings.ReplaceAll(buf.String(), "\n", "\r\n"))
+
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-redraw.C:
+			continue
+
+		case <-refresh.C:
+			startRefresh()
+
+		case result := <-refreshed:
+			selected = keepSelection(data, result, selected)
+			data, fetching = result, false
+			if outdated {
+				outdated = false
+				startRefresh()
+			}
+
+		case key := <-keys:
+			var incident *pagerduty.Incident
+			if selected < len(data.incidents) {
+				incident = &data.incidents[selected]
+			}
+
+			switch key {
+			case "quit":
+				return nil
+
+			case "up":
+				selected--
+
+			case "down":
+				selected++
+
+			case "refresh":
+				status = ""
+				startRefresh()
+
+			case "acknowledge", "resolve":
+				if incident == nil {
+					continue
+				}
+
+				status = changeIncidentStatus(ctx, client, user, *incident, map[string]string{"acknowledge": "acknowledged", "resolve": "resolved"}[key])
+				startRefresh()
+
+			case "open":
+				if incident == nil {
+					continue
+				}
+
+				status = bunt.Sprintf("Opened #%d in the browser", incident.IncidentNumber)
+				if err := openBrowser(incident.HTMLURL); err != nil {
+					status = bunt.Sprintf("FireBrick{failed to open the browser:} %v", err)
+				}
+			}
+		}
+	}
+}
+
+// keepSelection returns the position of the previously selected incident in
+// the refreshed data, or the unchanged position if the incident is gone
+func keepSelection(previous *dashboardData, result *dashboardData, selected int) int {
+	if selected < 0 || selected >= len(previous.incidents) {
+		return selected
+	}
+
+	for i, incident := range result.incidents {
+		if incident.ID == previous.incidents[selected].ID {
+			return i
+		}
+	}
+
+	return selected
+}
+
+// changeIncidentStatus sets the status of the incident and returns the
+// message describing the outcome
+func changeIncidentStatus(ctx context.Context, client pd.Client, user *pagerduty.User, incident pagerduty.Incident, status string) string {
+	results := pd.ManageIncidents(ctx, client, user.Email, []string{incident.ID}, pagerduty.ManageIncidentsOptions{Status: status})
+	if err := results[0].Err; err != nil {
+		return bunt.Sprintf("FireBrick{✗} #%d: %v", incident.IncidentNumber, err)
+	}
+
+	return bunt.Sprintf("SeaGreen{✓} #%d %s", incident.IncidentNumber, status)
+}
+
+// readDashboardKeys translates the keyboard input into dashboard actions
+// until done is closed, a pending read is interrupted using a read deadline
+// if the input supports it, otherwise the reader stops after the next input
+func readDashboardKeys(in io.Reader, done <-chan struct{}) <-chan string {
+	keys := make(chan string)
+
+	if deadline, ok := in.(interface{ SetReadDeadline(time.Time) error }); ok {
+		go func() {
+			<-done
+			_ = deadline.SetReadDeadline(time.Now())
+		}()
+	}
+
+	go func() {
+		defer close(keys)
+
+		buf := make([]byte, 16)
+		for {
+			n, err := in.Read(buf)
+			if err != nil {
+				select {
+				case keys <- "quit":
+				case <-done:
+				}
+
+				return
+			}
+
+			var key string
+			switch string(buf[:n]) {
+			case "q", "\x03", "\x04":
+				key = "quit"
+
+			case "k", "\x1b[A":
+				key = "up"
+
+			case "j", "\x1b[B":
+				key = "down"
+
+			case "a":
+				key = "acknowledge"
+
+			case "r":
+				key = "resolve"
+
+			case "o", "\r":
+				key = "open"
+
+			case " ":
+				key = "refresh"
+
+			default:
+				continue
+			}
+
+			select {
+			case keys <- key:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return keys
+}
+
+// openBrowser opens the URL with the default application of the system
+func openBrowser(url string) error {
+	var browser *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		browser = exec.Command("open", url)
+
+	case "windows":
+		browser = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+
+	default:
+		browser = exec.Command("xdg-open", url)
+	}
+
+	if err := browser.Start(); err != nil {
+		return err
+	}
+
+	go browser.Wait()
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(dashboardCmd)
+
+	dashboardCmd.Flags().DurationVar(&dashboardCmdSettings.interval, "interval", 30*time.Second, "time between two refreshes")
+}
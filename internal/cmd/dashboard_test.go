@@ -0,0 +1,125 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+// nextKey returns the next dashboard action or fails after a second
+func nextKey(t *testing.T, keys <-chan string) (string, bool) {
+	t.Helper()
+
+	select {
+	case key, ok := <-keys:
+		return key, ok
+
+	case <-time.After(time.Second):
+		t.Fatal("expected a key within a second")
+		return "", false
+	}
+}
+
+func TestReadDashboardKeys(t *testing.T) {
+	in, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer in.Close()
+	defer w.Close()
+
+	done := make(chan struct{})
+	keys := readDashboardKeys(in, done)
+
+	for _, tt := range []struct{ input, key string }{
+		{input: "j", key: "down"},
+		{input: "\x1b[A", key: "up"},
+		{input: " ", key: "refresh"},
+	} {
+		if _, err := w.WriteString(tt.input); err != nil {
+			t.Fatal(err)
+		}
+
+		if key, _ := nextKey(t, keys); key != tt.key {
+			t.Errorf("expected %q for input %q, got %q", tt.key, tt.input, key)
+		}
+	}
+
+	// closing done interrupts the pending read without any further input
+	close(done)
+	for {
+		if _, ok := nextKey(t, keys); !ok {
+			break
+		}
+	}
+}
+
+func TestReadDashboardKeysQuitsAtEndOfInput(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	// unknown input is ignored
+	keys := readDashboardKeys(strings.NewReader("x"), done)
+	if key, _ := nextKey(t, keys); key != "quit" {
+		t.Errorf("expected quit, got %q", key)
+	}
+
+	if _, ok := nextKey(t, keys); ok {
+		t.Error("expected no further keys")
+	}
+}
+
+func TestKeepSelection(t *testing.T) {
+	incidents := func(ids ...string) *dashboardData {
+		var data dashboardData
+		for _, id := range ids {
+			data.incidents = append(data.incidents, pagerduty.Incident{APIObject: pagerduty.APIObject{ID: id}})
+		}
+
+		return &data
+	}
+
+	tests := []struct {
+		name     string
+		previous *dashboardData
+		result   *dashboardData
+		selected int
+		expected int
+	}{
+		{name: "selected incident moved", previous: incidents("P1", "P2", "P3"), result: incidents("P0", "P1", "P2", "P3"), selected: 1, expected: 2},
+		{name: "selected incident gone", previous: incidents("P1", "P2", "P3"), result: incidents("P1", "P3"), selected: 1, expected: 1},
+		{name: "no incidents before", previous: incidents(), result: incidents("P1"), selected: 0, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if selected := keepSelection(tt.previous, tt.result, tt.selected); selected != tt.expected {
+				t.Errorf("expected selection %d, got %d", tt.expected, selected)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/gonvenience/bunt"
+	"github.com/homeport/pd/internal/pd"
+	"github.com/spf13/cobra"
+)
+
+// defaultHandoverTemplate is used if no handover template is configured
+const defaultHandoverTemplate = `Handover of {{.ShiftName}} from {{.Start}} to {{.End}} by {{.Username}}
+
+Incidents during the shift: {{len .Incidents}}
+{{range .Incidents}}  - #{{.IncidentNumber}} {{.Title}} ({{.Status}}) {{.HTMLURL}}
+{{end}}
+Still open incidents from before the shift: {{len .CarriedOverIncidents}}
+{{range .CarriedOverIncidents}}  - #{{.IncidentNumber}} {{.Title}} ({{.Status}}) {{.HTMLURL}}
+{{end}}`
+
+var handoverCmdSettings struct {
+	templateName string
+}
+
+// handoverCmd represents the handover command
+var handoverCmd = &cobra.Command{
+	Use:   "handover",
+	Args:  cobra.NoArgs,
+	Short: "Creates the handover report of the shift",
+	Long: `Creates a report of the incidents of your shift while it is in charge or
+of its last occurrence, or of the previous shift if no own shift is set, the
+report covers the incidents created during the shift in which you were
+involved and all incidents of your teams that were still open from before, it
+is based on the template named handover in the .pd.yml file or a built-in
+template`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var (
+			ctx = cmd.Context()
+			out = cmd.OutOrStdout()
+		)
+
+		shift, timeRange, err := pd.GetHandoverShift()
+		if err != nil {
+			return err
+		}
+
+		data, err := pd.GetTemplate(handoverCmdSettings.templateName)
+		if err != nil {
+			return err
+		}
+
+		// only a missing default template falls back to the built-in one
+		if data == "" {
+			if cmd.Flags().Changed("template") {
+				return fmt.Errorf("there is no template named %q in the .pd.yml file", handoverCmdSettings.templateName)
+			}
+
+			data = defaultHandoverTemplate
+		}
+
+		temp, err := newReportTemplate(data)
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		incidents, username, err := getRelevantIncidents(ctx, client, "", incidentFilter{
+			from:         timeRange.Start.Format(time.RFC3339),
+			to:           timeRange.End.Format(time.RFC3339),
+			involvements: pd.Involvements,
+		})
+		if err != nil {
+			return err
+		}
+
+		// incidents can stay open for longer than the default time range of
+		// the API, the next shift takes them over whoever was involved so far
+		open, _, err := getRelevantIncidents(ctx, client, "", incidentFilter{
+			allDates: true,
+			statuses: []string{"triggered", "acknowledged"},
+		})
+		if err != nil {
+			return err
+		}
+
+		var carriedOver []pagerduty.Incident
+		for _, incident := range open {
+			if createdAt, err := pd.ParseTimestamp(incident.CreatedAt); err == nil && createdAt.Before(timeRange.Start) {
+				carriedOver = append(carriedOver, incident)
+			}
+		}
+
+		input := struct {
+			Username             string
+			ShiftName            string
+			Date                 string
+			Start                string
+			End                  string
+			StartOfOwnShift      string
+			EndOfOwnShift        string
+			OwnShift             pd.TimeRange
+			Incidents            []pagerduty.Incident
+			CarriedOverIncidents []pagerduty.Incident
+		}{
+			Username:             username,
+			ShiftName:            shift.Name,
+			Date:                 pd.InTimezone(timeRange.Start).Format("2006-01-02"),
+			Start:                pd.InTimezone(timeRange.Start).Format("2006-01-02 15:04"),
+			End:                  pd.InTimezone(timeRange.End).Format("2006-01-02 15:04"),
+			StartOfOwnShift:      shift.Start.String(),
+			EndOfOwnShift:        shift.End.String(),
+			OwnShift:             timeRange,
+			Incidents:            incidents,
+			CarriedOverIncidents: carriedOver,
+		}
+
+		if isStructuredOutput() {
+			var report bytes.Buffer
+			if err := temp.Execute(&report, input); err != nil {
+				return err
+			}
+
+			result := handoverOutput{
+				Username: username,
+				Shift:    shift.Name,
+				Start:    timeRange.Start,
+				End:      timeRange.End,
+				Report:   report.String(),
+			}
+
+			if result.Incidents, err = newIncidentOutputs(ctx, client, incidents); err != nil {
+				return err
+			}
+
+			if result.CarriedOverIncidents, err = newIncidentOutputs(ctx, client, carriedOver); err != nil {
+				return err
+			}
+
+			return printStructured(out, result)
+		}
+
+		bunt.Fprintln(out)
+		return temp.Execute(out, input)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(handoverCmd)
+
+	handoverCmd.Flags().StringVar(&handoverCmdSettings.templateName, "template", "handover", "name of the template in the .pd.yml file")
+}
@@ -0,0 +1,110 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHandover(t *testing.T) {
+	withHandover := strings.Replace(testConfig, "templates:\n", "templates:\n  handover: \"Carried over: {{ range .CarriedOverIncidents }}#{{ .IncidentNumber }} {{ end }}\"\n", 1)
+
+	tests := []struct {
+		name       string
+		config     string
+		args       []string
+		expected   []string
+		unexpected []string
+		err        string
+	}{
+		{
+			name:   "built-in template without a handover template",
+			config: testConfig,
+			args:   []string{"handover"},
+			expected: []string{
+				"by Jane Doe",
+				"Still open incidents from before the shift: 2",
+				"  - #1 Disk full on db-1 (triggered) https://fake.pagerduty.com/incidents/PINC001",
+				"  - #2 Certificate expires (acknowledged) https://fake.pagerduty.com/incidents/PINC002",
+			},
+			unexpected: []string{"#3", "#4"},
+		},
+		{
+			name:     "configured handover template",
+			config:   withHandover,
+			args:     []string{"handover"},
+			expected: []string{"Carried over: #1 #2 "},
+		},
+		{
+			name:     "explicit template",
+			config:   withHandover,
+			args:     []string{"handover", "--template", "report"},
+			expected: []string{"Report of Jane Doe for "},
+		},
+		{
+			name:   "explicit template that does not exist",
+			config: withHandover,
+			args:   []string{"handover", "--template", "foo"},
+			err:    `there is no template named "foo" in the .pd.yml file`,
+		},
+		{
+			name:   "explicit default template that does not exist",
+			config: testConfig,
+			args:   []string{"handover", "--template", "handover"},
+			err:    `there is no template named "handover" in the .pd.yml file`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient()
+
+			// the carried over incidents do not depend on the involvement of the user
+			delete(client.LogEntries, "PINC002")
+
+			out, err := runCommand(t, client, tt.config, "", tt.args...)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(out, expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, out)
+				}
+			}
+
+			for _, unexpected := range tt.unexpected {
+				if strings.Contains(out, unexpected) {
+					t.Errorf("expected output not to contain %q, got:\n%s", unexpected, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,351 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/gonvenience/bunt"
+	"github.com/gonvenience/wrap"
+	"github.com/homeport/pd/internal/pd"
+	"github.com/spf13/cobra"
+)
+
+// incidentAction is one of the actions of the incident command, it is
+// applied to the incidents given as arguments or, without arguments, to the
+// incidents selected by the selection flags, the optional prepare function
+// runs once the incidents are known and before the confirmation
+type incidentAction struct {
+	name     string
+	short    string
+	verb     string
+	done     string
+	statuses []string
+	flags    func(cmd *cobra.Command)
+	prepare  func(cmd *cobra.Command, incidents []pagerduty.Incident) error
+	apply    func(ctx context.Context, client pd.Client, user *pagerduty.User, ids []string) ([]pd.IncidentResult, error)
+
+	selection incidentSelection
+	yes       bool
+}
+
+var incidentReassignCmdSettings struct {
+	users            []string
+	escalationPolicy string
+}
+
+var incidentSnoozeCmdSettings struct {
+	duration time.Duration
+}
+
+var incidentEscalateCmdSettings struct {
+	level uint
+}
+
+var incidentActions = []*incidentAction{
+	{
+		name:     "ack",
+		short:    "Acknowledge incidents",
+		verb:     "Acknowledge",
+		done:     "acknowledged",
+		statuses: []string{"triggered"},
+		apply: func(ctx context.Context, client pd.Client, user *pagerduty.User, ids []string) ([]pd.IncidentResult, error) {
+			return pd.ManageIncidents(ctx, client, user.Email, ids, pagerduty.ManageIncidentsOptions{Status: "acknowledged"}), nil
+		},
+	},
+	{
+		name:     "resolve",
+		short:    "Resolve incidents",
+		verb:     "Resolve",
+		done:     "resolved",
+		statuses: []string{"triggered", "acknowledged"},
+		apply: func(ctx context.Context, client pd.Client, user *pagerduty.User, ids []string) ([]pd.IncidentResult, error) {
+			return pd.ManageIncidents(ctx, client, user.Email, ids, pagerduty.ManageIncidentsOptions{Status: "resolved"}), nil
+		},
+	},
+	{
+		name:     "reassign",
+		short:    "Reassign incidents to users or an escalation policy",
+		verb:     "Reassign",
+		done:     "reassigned",
+		statuses: []string{"triggered", "acknowledged"},
+		flags: func(cmd *cobra.Command) {
+			cmd.Flags().StringSliceVar(&incidentReassignCmdSettings.users, "to-user", nil, "assign to user (ID, email, name, or me)")
+			cmd.Flags().StringVar(&incidentReassignCmdSettings.escalationPolicy, "to-escalation-policy", "", "assign to escalation policy (ID or name)")
+		},
+		apply: func(ctx context.Context, client pd.Client, user *pagerduty.User, ids []string) ([]pd.IncidentResult, error) {
+			assignees, err := resolveAssignees(ctx, client, incidentReassignCmdSettings.users)
+			if err != nil {
+				return nil, err
+			}
+
+			change := pagerduty.ManageIncidentsOptions{Assignments: assignees}
+
+			if incidentReassignCmdSettings.escalationPolicy != "" {
+				policy, err := pd.ResolveEscalationPolicy(ctx, client, incidentReassignCmdSettings.escalationPolicy)
+				if err != nil {
+					return nil, err
+				}
+
+				change.EscalationPolicy = &pagerduty.APIReference{ID: policy.ID, Type: "escalation_policy_reference"}
+			}
+
+			return pd.ManageIncidents(ctx, client, user.Email, ids, change), nil
+		},
+	},
+	{
+		name:     "snooze",
+		short:    "Snooze acknowledged incidents",
+		verb:     "Snooze",
+		done:     "snoozed",
+		statuses: []string{"acknowledged"},
+		flags: func(cmd *cobra.Command) {
+			cmd.Flags().DurationVar(&incidentSnoozeCmdSettings.duration, "for", 0, "snooze duration, like 30m or 4h")
+		},
+		apply: func(ctx context.Context, client pd.Client, _ *pagerduty.User, ids []string) ([]pd.IncidentResult, error) {
+			return pd.SnoozeIncidents(ctx, client, ids, incidentSnoozeCmdSettings.duration), nil
+		},
+	},
+	{
+		name:     "escalate",
+		short:    "Escalate incidents to an escalation level",
+		verb:     "Escalate",
+		done:     "escalated",
+		statuses: []string{"triggered", "acknowledged"},
+		flags: func(cmd *cobra.Command) {
+			cmd.Flags().UintVar(&incidentEscalateCmdSettings.level, "level", 0, "escalation level of the escalation policy to escalate to")
+		},
+		apply: func(ctx context.Context, client pd.Client, user *pagerduty.User, ids []string) ([]pd.IncidentResult, error) {
+			return pd.ManageIncidents(ctx, client, user.Email, ids, pagerduty.ManageIncidentsOptions{EscalationLevel: incidentEscalateCmdSettings.level}), nil
+		},
+	},
+}
+
+// validate checks the action specific flags before any incident is touched
+func (a *incidentAction) validate() error {
+	switch a.name {
+	case "reassign":
+		switch {
+		case len(incidentReassignCmdSettings.users) == 0 && incidentReassignCmdSettings.escalationPolicy == "":
+			return errors.New("use --to-user or --to-escalation-policy to select the new assignees")
+
+		case len(incidentReassignCmdSettings.users) > 0 && incidentReassignCmdSettings.escalationPolicy != "":
+			return errors.New("--to-user and --to-escalation-policy cannot be used together")
+		}
+
+	case "snooze":
+		if incidentSnoozeCmdSettings.duration < time.Minute {
+			return errors.New("use --for to set a snooze duration of at least one minute")
+		}
+
+	case "escalate":
+		if incidentEscalateCmdSettings.level == 0 {
+			return errors.New("use --level to set the escalation level to escalate to")
+		}
+	}
+
+	return nil
+}
+
+func (a *incidentAction) command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   a.name + " [incident-ID...]",
+		Short: a.short,
+		Long: fmt.Sprintf(`%s the incidents with the given IDs, or without IDs all incidents
+that are selected by the flags, before anything is changed, the incidents are
+listed for confirmation, use --yes to skip it`, a.verb),
+		RunE: a.run,
+	}
+
+	a.selection.addFlags(cmd)
+	cmd.Flags().BoolVarP(&a.yes, "yes", "y", false, "do not ask for confirmation")
+	if a.flags != nil {
+		a.flags(cmd)
+	}
+
+	return cmd
+}
+
+func (a *incidentAction) run(cmd *cobra.Command, args []string) error {
+	var (
+		ctx = cmd.Context()
+		out = cmd.OutOrStdout()
+	)
+
+	if len(args) > 0 && a.selection.changed(cmd) {
+		return errors.New("use either incident IDs or selection flags, not both")
+	}
+
+	if err := a.validate(); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	user, err := client.GetCurrentUserWithContext(ctx, pagerduty.GetCurrentUserOptions{})
+	if err != nil {
+		return wrap.Error(err, "it seems like the authtoken is not set correctly or outdated. Please update the authtoken in the .pd.yml file. If you don't know how to create your authtoken, this might help:\n https://support.pagerduty.com/docs/generating-api-keys#generating-a-personal-rest-api-key\n")
+	}
+
+	incidents, err := a.incidents(ctx, client, args)
+	if err != nil {
+		return err
+	}
+
+	if len(incidents) == 0 {
+		if isStructuredOutput() {
+			return printStructured(out, []incidentActionOutput{})
+		}
+
+		bunt.Fprintf(out, "\nThere are *no* incidents matching the selection.\n\n")
+		return nil
+	}
+
+	if a.prepare != nil {
+		if err := a.prepare(cmd, incidents); err != nil {
+			return err
+		}
+	}
+
+	if !a.yes {
+		bunt.Fprintln(cmd.ErrOrStderr())
+		for _, incident := range incidents {
+			bunt.Fprintf(cmd.ErrOrStderr(), "  #%d *%s* (%s)\n", incident.IncidentNumber, incident.Title, incident.Status)
+		}
+
+		ok, err := confirm(cmd, bunt.Sprintf("\n%s *%d* incident(s)?", a.verb, len(incidents)))
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			bunt.Fprintf(cmd.ErrOrStderr(), "Nothing was changed.\n")
+			return nil
+		}
+	}
+
+	ids := make([]string, len(incidents))
+	for i, incident := range incidents {
+		ids[i] = incident.ID
+	}
+
+	results, err := a.apply(ctx, client, user, ids)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	var output = make([]incidentActionOutput, len(results))
+	for i, result := range results {
+		output[i] = incidentActionOutput{
+			ID:     result.ID,
+			Number: incidents[i].IncidentNumber,
+			Title:  incidents[i].Title,
+			Action: a.name,
+		}
+
+		if result.Incident != nil {
+			output[i].Status = result.Incident.Status
+		}
+
+		if result.Err != nil {
+			failed++
+			output[i].Error = result.Err.Error()
+		}
+	}
+
+	if isStructuredOutput() {
+		if err := printStructured(out, output); err != nil {
+			return err
+		}
+	} else {
+		bunt.Fprintln(out)
+		for _, entry := range output {
+			if entry.Error != "" {
+				bunt.Fprintf(out, "  FireBrick{✗} #%d *%s*: %s\n", entry.Number, entry.Title, entry.Error)
+				continue
+			}
+
+			bunt.Fprintf(out, "  SeaGreen{✓} #%d *%s*: %s\n", entry.Number, entry.Title, a.done)
+		}
+
+		bunt.Fprintln(out)
+	}
+
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d of %d incidents could not be %s", failed, len(results), a.done)
+	}
+
+	return nil
+}
+
+// incidents returns the incidents with the given IDs, or the incidents
+// selected by the flags, which are limited to the statuses the action
+// applies to unless a status is selected explicitly, without a time range
+// incidents of all dates are selected since open incidents can be old
+func (a *incidentAction) incidents(ctx context.Context, client pd.Client, ids []string) ([]pagerduty.Incident, error) {
+	if len(ids) > 0 {
+		incidents, errs := pd.ParallelMap(ctx, pd.DefaultParallelism, ids, func(ctx context.Context, id string) (pagerduty.Incident, error) {
+			incident, err := client.GetIncidentWithContext(ctx, id)
+			if err != nil {
+				return pagerduty.Incident{}, wrap.Errorf(err, "failed to get incident %s", id)
+			}
+
+			return *incident, nil
+		})
+
+		if len(errs) > 0 {
+			return nil, wrap.Errors(errs, "failed to get incidents")
+		}
+
+		return incidents, nil
+	}
+
+	filter, err := a.selection.filter(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(filter.statuses) == 0 {
+		filter.statuses = a.statuses
+	}
+
+	if filter.from == "" && filter.to == "" {
+		filter.allDates = true
+	}
+
+	incidents, _, err := getRelevantIncidents(ctx, client, a.selection.id, filter)
+	return incidents, err
+}
+
+func init() {
+	for _, action := range incidentActions {
+		incidentCmd.AddCommand(action.command())
+	}
+}
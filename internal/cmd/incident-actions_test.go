@@ -0,0 +1,231 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/homeport/pd/internal/pd"
+)
+
+// incidentListRecorder records the options of all incident listings
+type incidentListRecorder struct {
+	*pd.FakeClient
+
+	mutex   sync.Mutex
+	options []pagerduty.ListIncidentsOptions
+}
+
+func (r *incidentListRecorder) ListIncidentsWithContext(ctx context.Context, o pagerduty.ListIncidentsOptions) (*pagerduty.ListIncidentsResponse, error) {
+	r.mutex.Lock()
+	r.options = append(r.options, o)
+	r.mutex.Unlock()
+
+	return r.FakeClient.ListIncidentsWithContext(ctx, o)
+}
+
+func incidentStatuses(t *testing.T, client *pd.FakeClient) map[string]string {
+	t.Helper()
+
+	result := map[string]string{}
+	for _, incident := range client.Incidents {
+		result[incident.ID] = incident.Status
+	}
+
+	return result
+}
+
+func TestIncidentActionConfirmation(t *testing.T) {
+	tests := []struct {
+		name       string
+		stdin      string
+		args       []string
+		expected   []string
+		unexpected []string
+		status     string
+	}{
+		{
+			name:     "declined",
+			stdin:    "n\n",
+			args:     []string{"incident", "ack"},
+			expected: []string{"#1 Disk full on db-1 (triggered)", "Acknowledge 1 incident(s)? [y/N]", "Nothing was changed."},
+			status:   "triggered",
+		},
+		{
+			name:     "no answer",
+			args:     []string{"incident", "ack"},
+			expected: []string{"Acknowledge 1 incident(s)? [y/N]", "Nothing was changed."},
+			status:   "triggered",
+		},
+		{
+			name:     "confirmed",
+			stdin:    "yes\n",
+			args:     []string{"incident", "ack"},
+			expected: []string{"Acknowledge 1 incident(s)? [y/N]", "✓ #1 Disk full on db-1: acknowledged"},
+			status:   "acknowledged",
+		},
+		{
+			name:       "confirmation skipped",
+			args:       []string{"incident", "ack", "--yes"},
+			expected:   []string{"✓ #1 Disk full on db-1: acknowledged"},
+			unexpected: []string{"[y/N]"},
+			status:     "acknowledged",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient()
+
+			out, err := runCommand(t, client, testConfig, tt.stdin, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(out, expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, out)
+				}
+			}
+
+			for _, unexpected := range tt.unexpected {
+				if strings.Contains(out, unexpected) {
+					t.Errorf("expected output not to contain %q, got:\n%s", unexpected, out)
+				}
+			}
+
+			if status := incidentStatuses(t, client)["PINC001"]; status != tt.status {
+				t.Errorf("expected incident to be %s, got %s", tt.status, status)
+			}
+		})
+	}
+}
+
+func TestIncidentActionDefaultStatuses(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+		statuses map[string]string
+	}{
+		{
+			name:     "ack selects triggered incidents",
+			args:     []string{"incident", "ack", "--yes"},
+			expected: []string{"✓ #1 Disk full on db-1: acknowledged"},
+			statuses: map[string]string{"PINC001": "acknowledged", "PINC002": "acknowledged", "PINC003": "resolved"},
+		},
+		{
+			name:     "resolve selects triggered and acknowledged incidents",
+			args:     []string{"incident", "resolve", "--yes"},
+			expected: []string{"✓ #1 Disk full on db-1: resolved", "✓ #2 Certificate expires: resolved"},
+			statuses: map[string]string{"PINC001": "resolved", "PINC002": "resolved", "PINC003": "resolved"},
+		},
+		{
+			name:     "snooze selects acknowledged incidents",
+			args:     []string{"incident", "snooze", "--for", "1h", "--yes"},
+			expected: []string{"✓ #2 Certificate expires: snoozed"},
+			statuses: map[string]string{"PINC001": "triggered", "PINC002": "acknowledged", "PINC003": "resolved"},
+		},
+		{
+			name:     "explicit status",
+			args:     []string{"incident", "resolve", "--status", "acknowledged", "--yes"},
+			expected: []string{"✓ #2 Certificate expires: resolved"},
+			statuses: map[string]string{"PINC001": "triggered", "PINC002": "resolved", "PINC003": "resolved"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient()
+
+			out, err := runCommand(t, client, testConfig, "", tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(out, expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, out)
+				}
+			}
+
+			if count := strings.Count(out, "✓"); count != len(tt.expected) {
+				t.Errorf("expected %d changed incidents, got %d:\n%s", len(tt.expected), count, out)
+			}
+
+			statuses := incidentStatuses(t, client)
+			for id, expected := range tt.statuses {
+				if statuses[id] != expected {
+					t.Errorf("expected incident %s to be %s, got %s", id, expected, statuses[id])
+				}
+			}
+		})
+	}
+}
+
+func TestIncidentActionDateRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		dateRange string
+	}{
+		{name: "without time range", args: []string{"incident", "ack", "--yes"}, dateRange: "all"},
+		{name: "with start", args: []string{"incident", "ack", "--from", "2022-11-07", "--yes"}},
+		{name: "with end", args: []string{"incident", "ack", "--to", "2022-11-07", "--yes"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &incidentListRecorder{FakeClient: newTestClient()}
+
+			if _, err := runCommand(t, client, testConfig, "", tt.args...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(client.options) != 1 {
+				t.Fatalf("expected one incident listing, got %d", len(client.options))
+			}
+
+			if dateRange := client.options[0].DateRange; dateRange != tt.dateRange {
+				t.Errorf("expected date range %q, got %q", tt.dateRange, dateRange)
+			}
+		})
+	}
+}
+
+func TestIncidentActionPartialFailure(t *testing.T) {
+	client := newTestClient()
+
+	out, err := runCommand(t, client, testConfig, "", "incident", "snooze", "--for", "1h", "--yes", "PINC001", "PINC002")
+	if err == nil || err.Error() != "1 of 2 incidents could not be snoozed" {
+		t.Fatalf("expected one of two incidents to fail, got %v", err)
+	}
+
+	for _, expected := range []string{"✗ #1 Disk full on db-1:", "is not acknowledged", "✓ #2 Certificate expires: snoozed"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, out)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/gonvenience/bunt"
+	"github.com/gonvenience/wrap"
+	"github.com/homeport/pd/internal/pd"
+	"github.com/spf13/cobra"
+)
+
+var incidentCreateCmdSettings struct {
+	service string
+	title   string
+	urgency string
+	assign  []string
+	details string
+}
+
+// incidentCreateCmd represents the incident create command
+var incidentCreateCmd = &cobra.Command{
+	Use:   "create",
+	Args:  cobra.NoArgs,
+	Short: "Create an incident",
+	Long: `Creates an incident for the service, for example to page another team, the
+incident is assigned to the given users or else according to the escalation
+policy of the service`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if incidentCreateCmdSettings.service == "" || incidentCreateCmdSettings.title == "" {
+			return errors.New("use --service and --title to describe the incident")
+		}
+
+		if err := validateUrgency(incidentCreateCmdSettings.urgency); err != nil {
+			return err
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		service, err := pd.ResolveService(ctx, client, incidentCreateCmdSettings.service)
+		if err != nil {
+			return err
+		}
+
+		assignees, err := resolveAssignees(ctx, client, incidentCreateCmdSettings.assign)
+		if err != nil {
+			return err
+		}
+
+		options := pagerduty.CreateIncidentOptions{
+			Title:       incidentCreateCmdSettings.title,
+			Service:     &pagerduty.APIReference{ID: service.ID, Type: "service_reference"},
+			Urgency:     incidentCreateCmdSettings.urgency,
+			Assignments: assignees,
+		}
+
+		if incidentCreateCmdSettings.details != "" {
+			options.Body = &pagerduty.APIDetails{Type: "incident_body", Details: incidentCreateCmdSettings.details}
+		}
+
+		return createIncident(cmd, client, options)
+	},
+}
+
+// createIncident creates the incident on behalf of the current user and
+// prints it together with its link
+func createIncident(cmd *cobra.Command, client pd.Client, options pagerduty.CreateIncidentOptions) error {
+	var (
+		ctx = cmd.Context()
+		out = cmd.OutOrStdout()
+	)
+
+	user, err := client.GetCurrentUserWithContext(ctx, pagerduty.GetCurrentUserOptions{})
+	if err != nil {
+		return wrap.Error(err, "it seems like the authtoken is not set correctly or outdated. Please update the authtoken in the .pd.yml file. If you don't know how to create your authtoken, this might help:\n https://support.pagerduty.com/docs/generating-api-keys#generating-a-personal-rest-api-key\n")
+	}
+
+	incident, err := client.CreateIncidentWithContext(ctx, user.Email, &options)
+	if err != nil {
+		return wrap.Error(err, "failed to create incident")
+	}
+
+	if isStructuredOutput() {
+		result, err := newIncidentOutput(ctx, client, *incident)
+		if err != nil {
+			return err
+		}
+
+		return printStructured(out, result)
+	}
+
+	bunt.Fprintf(out, "\nCreated incident #%d *%s* (%s urgency) for service SkyBlue{%s}\n", incident.IncidentNumber, incident.Title, incident.Urgency, incident.Service.Summary)
+	bunt.Fprintf(out, "   *Link:* CornflowerBlue{~%s~}\n\n", incident.HTMLURL)
+	return nil
+}
+
+// resolveAssignees returns the assignees for the users given by ID, email,
+// name, or me
+func resolveAssignees(ctx context.Context, client pd.Client, values []string) ([]pagerduty.Assignee, error) {
+	var assignees []pagerduty.Assignee
+	for _, value := range values {
+		user, err := pd.ResolveUser(ctx, client, value)
+		if err != nil {
+			return nil, err
+		}
+
+		assignees = append(assignees, pagerduty.Assignee{
+			Assignee: pagerduty.APIObject{ID: user.ID, Type: "user_reference"},
+		})
+	}
+
+	return assignees, nil
+}
+
+func validateUrgency(urgency string) error {
+	switch urgency {
+	case "", "high", "low":
+		return nil
+
+	default:
+		return fmt.Errorf("unknown urgency %q, supported are: high, low", urgency)
+	}
+}
+
+func init() {
+	incidentCmd.AddCommand(incidentCreateCmd)
+
+	incidentCreateCmd.Flags().StringVar(&incidentCreateCmdSettings.service, "service", "", "service of the incident (ID or name)")
+	incidentCreateCmd.Flags().StringVar(&incidentCreateCmdSettings.title, "title", "", "title of the incident")
+	incidentCreateCmd.Flags().StringVar(&incidentCreateCmdSettings.urgency, "urgency", "", "urgency of the incident, high or low (defaults to the urgency of the service)")
+	incidentCreateCmd.Flags().StringSliceVar(&incidentCreateCmdSettings.assign, "assign", nil, "assign to user (ID, email, name, or me) instead of the escalation policy of the service")
+	incidentCreateCmd.Flags().StringVar(&incidentCreateCmdSettings.details, "details", "", "description of the incident")
+}
@@ -0,0 +1,147 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/homeport/pd/internal/pd"
+)
+
+// createdIncident returns the incident that was added to the fake client by
+// the command, the test client starts with four incidents
+func createdIncident(t *testing.T, client *pd.FakeClient) (string, string, string, []string) {
+	t.Helper()
+
+	if len(client.Incidents) != 5 {
+		t.Fatalf("expected one incident to be created, got %d new ones", len(client.Incidents)-4)
+	}
+
+	incident := client.Incidents[4]
+
+	var assignees []string
+	for _, assignment := range incident.Assignments {
+		assignees = append(assignees, assignment.Assignee.ID)
+	}
+
+	return incident.Title, incident.Service.ID, incident.Urgency, assignees
+}
+
+func TestIncidentCreate(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		expected  []string
+		service   string
+		urgency   string
+		assignees []string
+		err       string
+	}{
+		{
+			name: "service by name",
+			args: []string{"incident", "create", "--service", "checkout api", "--title", "Disk full on db-2"},
+			expected: []string{
+				"Created incident #5 Disk full on db-2 (high urgency) for service Checkout API",
+				"Link: https://fake.pagerduty.com/incidents/PFAKE05",
+			},
+			service:   "PSERV01",
+			urgency:   "high",
+			assignees: []string{"PUSER01"},
+		},
+		{
+			name: "service by ID assigned to users",
+			args: []string{"incident", "create", "--service", "PSERV02", "--title", "Disk full on db-2", "--urgency", "low", "--assign", "john@example.com,Johanna"},
+			expected: []string{
+				"Created incident #5 Disk full on db-2 (low urgency) for service Checkout Worker",
+				"Link: https://fake.pagerduty.com/incidents/PFAKE05",
+			},
+			service:   "PSERV02",
+			urgency:   "low",
+			assignees: []string{"PUSER02", "PUSER03"},
+		},
+		{
+			name:     "structured output",
+			args:     []string{"incident", "create", "--service", "Checkout API", "--title", "Disk full on db-2", "--output", "json"},
+			expected: []string{`"id": "PFAKE05"`, `"url": "https://fake.pagerduty.com/incidents/PFAKE05"`},
+			service:  "PSERV01",
+			urgency:  "high",
+		},
+		{
+			name: "ambiguous service",
+			args: []string{"incident", "create", "--service", "Checkout", "--title", "Disk full on db-2"},
+			err:  `service "Checkout" is ambiguous, it matches: Checkout API, Checkout Worker`,
+		},
+		{
+			name: "ambiguous user",
+			args: []string{"incident", "create", "--service", "Checkout API", "--title", "Disk full on db-2", "--assign", "Joh"},
+			err:  `user "Joh" is ambiguous, it matches: John Roe, Johanna Poe`,
+		},
+		{
+			name: "unknown service",
+			args: []string{"incident", "create", "--service", "Billing", "--title", "Disk full on db-2"},
+			err:  `there is no service matching "Billing"`,
+		},
+		{
+			name: "missing title",
+			args: []string{"incident", "create", "--service", "Checkout API"},
+			err:  "use --service and --title to describe the incident",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient()
+
+			out, err := runCommand(t, client, testConfig, "", tt.args...)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+
+				if len(client.Incidents) != 4 {
+					t.Errorf("expected no incident to be created")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(out, expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, out)
+				}
+			}
+
+			title, service, urgency, assignees := createdIncident(t, client)
+			if title != "Disk full on db-2" || service != tt.service || urgency != tt.urgency {
+				t.Errorf("expected incident %q of service %s with %s urgency, got %q of service %s with %s urgency", "Disk full on db-2", tt.service, tt.urgency, title, service, urgency)
+			}
+
+			if tt.assignees != nil && strings.Join(assignees, ",") != strings.Join(tt.assignees, ",") {
+				t.Errorf("expected assignees %v, got %v", tt.assignees, assignees)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/gonvenience/wrap"
+	"github.com/homeport/pd/internal/pd"
+	"github.com/spf13/cobra"
+)
+
+var incidentNoteCmdSettings struct {
+	message      string
+	templateName string
+	content      string
+}
+
+var incidentNote = &incidentAction{
+	name:     "note",
+	short:    "Add a note to incidents",
+	verb:     "Add the note to",
+	done:     "note added",
+	statuses: []string{"triggered", "acknowledged"},
+	flags: func(cmd *cobra.Command) {
+		cmd.Flags().StringVarP(&incidentNoteCmdSettings.message, "message", "m", "", "note text, use - to read it from standard input")
+		cmd.Flags().StringVar(&incidentNoteCmdSettings.templateName, "template", "", "name of the template in the .pd.yml file to pre-fill the editor with")
+	},
+	prepare: func(cmd *cobra.Command, incidents []pagerduty.Incident) error {
+		content, err := readNote(cmd, incidents)
+		if err != nil {
+			return err
+		}
+
+		if content == "" {
+			return errors.New("the note is empty, no note was added")
+		}
+
+		incidentNoteCmdSettings.content = content
+		return nil
+	},
+	apply: func(ctx context.Context, client pd.Client, user *pagerduty.User, ids []string) ([]pd.IncidentResult, error) {
+		return pd.AddIncidentNotes(ctx, client, user.Email, ids, incidentNoteCmdSettings.content), nil
+	},
+}
+
+// readNote returns the note text given by the --message flag, piped into
+// standard input, or written in an editor session, in that order
+func readNote(cmd *cobra.Command, incidents []pagerduty.Incident) (string, error) {
+	yes, _ := cmd.Flags().GetBool("yes")
+
+	switch {
+	case incidentNoteCmdSettings.message != "" && incidentNoteCmdSettings.message != "-":
+		return strings.TrimSpace(incidentNoteCmdSettings.message), nil
+
+	case incidentNoteCmdSettings.message == "-" || !isTerminal(cmd.InOrStdin()):
+		if !yes {
+			return "", errors.New("standard input cannot be used for both the note and the confirmation, use --yes to skip the confirmation")
+		}
+
+		data, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return "", wrap.Error(err, "failed to read note from standard input")
+		}
+
+		return strings.TrimSpace(string(data)), nil
+
+	default:
+		return editNote(incidents)
+	}
+}
+
+// noteCommentPrefix marks the lines of the editor session that are removed
+// from the note, other lines starting with # are kept as they are
+const noteCommentPrefix = "# pd:"
+
+// editNote opens the editor of $VISUAL or $EDITOR with a note, which is
+// pre-filled with the rendered template, lines starting with "# pd:" are
+// removed from the result
+func editNote(incidents []pagerduty.Incident) (string, error) {
+	var note strings.Builder
+	if incidentNoteCmdSettings.templateName != "" {
+		data, err := pd.GetTemplate(incidentNoteCmdSettings.templateName)
+		if err != nil {
+			return "", err
+		}
+
+		if data == "" {
+			return "", fmt.Errorf("there is no template named %q in the .pd.yml file", incidentNoteCmdSettings.templateName)
+		}
+
+		// the note is plain text, so unlike the reports it is not HTML escaped
+		temp, err := template.New("note").Funcs(template.FuncMap(reportTemplateFuncs)).Parse(data)
+		if err != nil {
+			return "", wrap.Error(err, "failed to parse the template")
+		}
+
+		input := struct {
+			Date      string
+			Incidents []pagerduty.Incident
+		}{
+			Date:      pd.InTimezone(time.Now()).Format("2006-01-02"),
+			Incidents: incidents,
+		}
+
+		if err := temp.Execute(&note, input); err != nil {
+			return "", wrap.Error(err, "failed to render the template")
+		}
+	}
+
+	var buf strings.Builder
+	buf.WriteString(strings.TrimRight(note.String(), "\n"))
+	fmt.Fprintf(&buf, "\n\n%s Write the note for the incidents below, lines starting with %q\n", noteCommentPrefix, noteCommentPrefix)
+	fmt.Fprintf(&buf, "%s are ignored, an empty note adds no note.\n%s\n", noteCommentPrefix, noteCommentPrefix)
+	for _, incident := range incidents {
+		fmt.Fprintf(&buf, "%s   #%d %s (%s)\n", noteCommentPrefix, incident.IncidentNumber, incident.Title, incident.Status)
+	}
+
+	file, err := os.CreateTemp("", "pd-note-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(buf.String()); err != nil {
+		file.Close()
+		return "", err
+	}
+
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+
+	if editor == "" {
+		editor = "vi"
+	}
+
+	fields := strings.Fields(editor)
+	session := exec.Command(fields[0], append(fields[1:], file.Name())...)
+	session.Stdin, session.Stdout, session.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := session.Run(); err != nil {
+		return "", wrap.Errorf(err, "failed to run editor %s", editor)
+	}
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, noteCommentPrefix) {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
+
+func init() {
+	incidentCmd.AddCommand(incidentNote.command())
+}
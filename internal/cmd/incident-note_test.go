@@ -0,0 +1,192 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+func TestIncidentNote(t *testing.T) {
+	tests := []struct {
+		name  string
+		stdin string
+		args  []string
+		notes map[string]string
+		err   string
+	}{
+		{
+			name:  "note from the message flag",
+			args:  []string{"incident", "note", "--message", "Disk cleaned up", "--yes", "PINC001", "PINC002"},
+			notes: map[string]string{"PINC001": "Disk cleaned up", "PINC002": "Disk cleaned up"},
+		},
+		{
+			name:  "note from standard input",
+			stdin: "Handed over to <Team Foo> & 'Team Bar'\n",
+			args:  []string{"incident", "note", "--yes", "PINC001"},
+			notes: map[string]string{"PINC001": "Handed over to <Team Foo> & 'Team Bar'"},
+		},
+		{
+			name:  "note from standard input using the message flag",
+			stdin: "Watching the trend\n",
+			args:  []string{"incident", "note", "--message", "-", "--yes", "--status", "acknowledged"},
+			notes: map[string]string{"PINC002": "Watching the trend"},
+		},
+		{
+			name:  "note from standard input without skipping the confirmation",
+			stdin: "Watching the trend\n",
+			args:  []string{"incident", "note", "PINC001"},
+			err:   "standard input cannot be used for both the note and the confirmation",
+		},
+		{
+			name:  "empty note",
+			stdin: "\n",
+			args:  []string{"incident", "note", "--yes", "PINC001"},
+			err:   "the note is empty, no note was added",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient()
+			delete(client.Notes, "PINC002")
+
+			_, err := runCommand(t, client, testConfig, tt.stdin, tt.args...)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+
+				if len(client.Notes["PINC001"]) > 0 || len(client.Notes["PINC002"]) > 0 {
+					t.Errorf("expected no note to be added, got %v", client.Notes)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, id := range []string{"PINC001", "PINC002"} {
+				var content []string
+				for _, note := range client.Notes[id] {
+					content = append(content, note.Content)
+
+					if note.User.Summary != "jane@example.com" {
+						t.Errorf("expected note to be added by jane@example.com, got %q", note.User.Summary)
+					}
+				}
+
+				expected, ok := tt.notes[id]
+				switch {
+				case !ok && len(content) > 0:
+					t.Errorf("expected no note on incident %s, got %q", id, content)
+
+				case ok && (len(content) != 1 || content[0] != expected):
+					t.Errorf("expected note %q on incident %s, got %q", expected, id, content)
+				}
+			}
+		})
+	}
+}
+
+func TestEditNote(t *testing.T) {
+	home := t.TempDir()
+	config := "authtoken: fake\ntemplates:\n  handover: \"Handover of {{ range .Incidents }}#{{ .IncidentNumber }} {{ .Title }}{{ end }}\"\n"
+	if err := os.WriteFile(filepath.Join(home, ".pd.yml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the editor keeps a copy of the pre-filled note and adds two lines
+	editor := filepath.Join(home, "editor.sh")
+	script := "#!/bin/sh\ncp \"$1\" \"$1.orig\"\nprintf '# Summary\\nChecked & done\\n' >> \"$1\"\ncp \"$1.orig\" " + filepath.Join(home, "prefilled.txt") + "\n"
+	if err := os.WriteFile(editor, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HOME", home)
+	t.Setenv("VISUAL", editor)
+
+	incidents := []pagerduty.Incident{{IncidentNumber: 1, Title: "Disk <full> & 'db-1'", Status: "triggered"}}
+
+	tests := []struct {
+		name      string
+		template  string
+		note      string
+		prefilled string
+		err       string
+	}{
+		{
+			name:      "without template",
+			note:      "# Summary\nChecked & done",
+			prefilled: "\n\n# pd: Write the note for the incidents below",
+		},
+		{
+			name:      "with template",
+			template:  "handover",
+			note:      "Handover of #1 Disk <full> & 'db-1'\n\n# Summary\nChecked & done",
+			prefilled: "Handover of #1 Disk <full> & 'db-1'\n\n# pd: Write the note",
+		},
+		{
+			name:     "unknown template",
+			template: "unknown",
+			err:      `there is no template named "unknown" in the .pd.yml file`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			incidentNoteCmdSettings.templateName = tt.template
+			t.Cleanup(func() { incidentNoteCmdSettings.templateName = "" })
+
+			note, err := editNote(incidents)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if note != tt.note {
+				t.Errorf("expected note %q, got %q", tt.note, note)
+			}
+
+			prefilled, err := os.ReadFile(filepath.Join(home, "prefilled.txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.HasPrefix(string(prefilled), tt.prefilled) || !strings.Contains(string(prefilled), "# pd:   #1 Disk <full> & 'db-1' (triggered)\n") {
+				t.Errorf("expected the editor to be pre-filled with %q and the incidents, got %q", tt.prefilled, prefilled)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gonvenience/wrap"
+	"github.com/homeport/pd/internal/pd"
+	"github.com/spf13/cobra"
+)
+
+// incidentSelection holds the flags that select incidents, they are shared
+// by all commands that work on a selection of incidents
+type incidentSelection struct {
+	id                 string
+	from               string
+	to                 string
+	involvement        []string
+	statuses           []string
+	urgencies          []string
+	priorities         []string
+	services           []string
+	teams              []string
+	escalationPolicies []string
+	match              string
+}
+
+// addFlags registers the selection flags for the command
+func (s *incidentSelection) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&s.id, "id", "", "use custom ID")
+	cmd.Flags().StringSliceVar(&s.involvement, "involvement", nil, "only incidents the user was assigned to, acknowledged, resolved, or notified about (default all of them)")
+	cmd.Flags().StringVar(&s.from, "from", "", "set startpoint of custom time period (date, RFC3339 time, duration like 24h, or yesterday, this-week, last-shift, ...)")
+	cmd.Flags().StringVar(&s.to, "to", "", "set endpoint of custom time period (same formats as --from)")
+	cmd.Flags().StringSliceVar(&s.statuses, "status", nil, "only alerts with status (triggered, acknowledged, resolved)")
+	cmd.Flags().StringSliceVar(&s.urgencies, "urgency", nil, "only alerts with urgency (high, low)")
+	cmd.Flags().StringSliceVar(&s.priorities, "priority", nil, "only alerts with priority (name like P1, or ID)")
+	cmd.Flags().StringSliceVar(&s.services, "service", nil, "only alerts of service (ID or name)")
+	cmd.Flags().StringSliceVar(&s.teams, "team", nil, "only alerts of team (ID or name), instead of the teams of the user")
+	cmd.Flags().StringSliceVar(&s.escalationPolicies, "escalation-policy", nil, "only alerts of escalation policy (ID or name)")
+	cmd.Flags().StringVar(&s.match, "match", "", "only alerts with title or description matching the regular expression")
+}
+
+// changed returns whether any of the selection flags was used
+func (s *incidentSelection) changed(cmd *cobra.Command) bool {
+	for _, name := range []string{"id", "involvement", "from", "to", "status", "urgency", "priority", "service", "team", "escalation-policy", "match"} {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filter creates the incident filter based on the flags, names of services,
+// teams, and escalation policies are resolved to IDs
+func (s *incidentSelection) filter(ctx context.Context, client pd.Client) (incidentFilter, error) {
+	var filter = incidentFilter{
+		priorities: s.priorities,
+	}
+
+	var err error
+	if s.from != "" {
+		timeRange, err := pd.ParseTimeExpression(s.from, time.Now())
+		if err != nil {
+			return filter, err
+		}
+
+		filter.from = timeRange.Start.Format(time.RFC3339)
+	}
+
+	if s.to != "" {
+		timeRange, err := pd.ParseTimeExpression(s.to, time.Now())
+		if err != nil {
+			return filter, err
+		}
+
+		filter.to = timeRange.End.Format(time.RFC3339)
+	}
+
+	if filter.involvements, err = pd.ParseInvolvements(s.involvement); err != nil {
+		return filter, err
+	}
+
+	for _, status := range s.statuses {
+		if !contains([]string{"triggered", "acknowledged", "resolved"}, status) {
+			return filter, fmt.Errorf("unknown status %q, supported are: triggered, acknowledged, resolved", status)
+		}
+
+		filter.statuses = append(filter.statuses, status)
+	}
+
+	for _, urgency := range s.urgencies {
+		if !contains([]string{"high", "low"}, urgency) {
+			return filter, fmt.Errorf("unknown urgency %q, supported are: high, low", urgency)
+		}
+
+		filter.urgencies = append(filter.urgencies, urgency)
+	}
+
+	if s.match != "" {
+		if filter.match, err = regexp.Compile(s.match); err != nil {
+			return filter, wrap.Errorf(err, "invalid regular expression %q", s.match)
+		}
+	}
+
+	for _, value := range s.services {
+		service, err := pd.ResolveService(ctx, client, value)
+		if err != nil {
+			return filter, err
+		}
+
+		filter.serviceIDs = append(filter.serviceIDs, service.ID)
+	}
+
+	for _, value := range s.teams {
+		team, err := pd.ResolveTeam(ctx, client, value)
+		if err != nil {
+			return filter, err
+		}
+
+		filter.teamIDs = append(filter.teamIDs, team.ID)
+	}
+
+	for _, value := range s.escalationPolicies {
+		policy, err := pd.ResolveEscalationPolicy(ctx, client, value)
+		if err != nil {
+			return filter, err
+		}
+
+		filter.escalationPolicyIDs = append(filter.escalationPolicyIDs, policy.ID)
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,183 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gonvenience/bunt"
+	"github.com/gonvenience/neat"
+	"github.com/homeport/pd/internal/pd"
+	"github.com/spf13/cobra"
+)
+
+// incidentShowCmd represents the incident show command
+var incidentShowCmd = &cobra.Command{
+	Use:   "show <incident-ID>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Show an incident with its timeline",
+	Long: `Shows the details of an incident and the timeline of its log entries and
+notes, like triggers, notifications, acknowledgements, escalations,
+reassignments, and resolutions, together with the time to acknowledge and the
+time to resolve`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		timeline, err := pd.GetIncidentTimeline(cmd.Context(), client, args[0])
+		if err != nil {
+			return err
+		}
+
+		if isStructuredOutput() {
+			return printStructured(out, newIncidentTimelineOutput(timeline))
+		}
+
+		incident := timeline.Incident
+		bunt.Fprintf(out, "\n#%d *%s* (%s, %s urgency)\n", incident.IncidentNumber, incident.Title, incident.Status, incident.Urgency)
+
+		if incident.Description != "" && incident.Description != incident.Title {
+			bunt.Fprintf(out, "   *Description:* \n")
+			for _, line := range strings.Split(incident.Description, "\n") {
+				bunt.Fprintf(out, "      %s\n", line)
+			}
+		}
+
+		bunt.Fprintf(out, "   *Service:* %s\n", incident.Service.Summary)
+		bunt.Fprintf(out, "   *Link:* CornflowerBlue{~%s~}\n", incident.HTMLURL)
+		bunt.Fprintf(out, "   *Triggered:* %s (%s ago)\n",
+			pd.InTimezone(timeline.Triggered).Format("2006-01-02 15:04:05"),
+			formatDuration(time.Since(timeline.Triggered)),
+		)
+
+		if d, ok := timeline.TimeToAcknowledge(); ok {
+			bunt.Fprintf(out, "   *Time to acknowledge:* %s\n", formatDuration(d))
+		}
+
+		if d, ok := timeline.TimeToResolve(); ok {
+			bunt.Fprintf(out, "   *Time to resolve:* %s\n", formatDuration(d))
+		}
+
+		bunt.Fprintln(out)
+
+		if len(timeline.Events) == 0 {
+			bunt.Fprintf(out, "There are *no* log entries for this incident.\n\n")
+			return nil
+		}
+
+		var table = [][]string{{
+			bunt.Sprint("*Time*"),
+			bunt.Sprint("*Since trigger*"),
+			bunt.Sprint("*Since previous*"),
+			bunt.Sprint("*Step*"),
+			bunt.Sprint("*Actor*"),
+			bunt.Sprint("*Summary*"),
+		}}
+
+		for i, event := range timeline.Events {
+			sincePrevious := ""
+			if i > 0 {
+				sincePrevious = "+" + formatDuration(event.Time.Sub(timeline.Events[i-1].Time))
+			}
+
+			table = append(table, []string{
+				pd.InTimezone(event.Time).Format("2006-01-02 15:04:05"),
+				"+" + formatDuration(event.Time.Sub(timeline.Triggered)),
+				sincePrevious,
+				timelineStep(event.Type),
+				event.Actor,
+				strings.Join(strings.Fields(event.Summary), " "),
+			})
+		}
+
+		content, err := neat.Table(table, neat.VertialBarSeparator())
+		if err != nil {
+			return err
+		}
+
+		neat.Box(
+			out,
+			bunt.Sprintf("*timeline* of incident *#%d*", incident.IncidentNumber),
+			strings.NewReader(content),
+			neat.HeadlineColor(bunt.LightSteelBlue),
+			neat.NoLineWrap(),
+		)
+
+		bunt.Fprintln(out)
+		return nil
+	},
+}
+
+// timelineStep returns the colored name of the timeline step
+func timelineStep(step string) string {
+	switch step {
+	case "trigger":
+		return bunt.Sprintf("FireBrick{%s}", step)
+
+	case "acknowledge":
+		return bunt.Sprintf("Gold{%s}", step)
+
+	case "resolve":
+		return bunt.Sprintf("SeaGreen{%s}", step)
+
+	case "escalate", "assign":
+		return bunt.Sprintf("Coral{%s}", step)
+
+	case "note":
+		return bunt.Sprintf("SkyBlue{%s}", step)
+
+	default:
+		return step
+	}
+}
+
+// formatDuration returns the duration with its two most significant units,
+// like 2d 4h, 3h 12m, 4m 10s, or 25s
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+
+	seconds := int64(d.Round(time.Second).Seconds())
+	switch {
+	case seconds < 60:
+		return fmt.Sprintf("%ds", seconds)
+
+	case seconds < 60*60:
+		return fmt.Sprintf("%dm %ds", seconds/60, seconds%60)
+
+	case seconds < 24*60*60:
+		return fmt.Sprintf("%dh %dm", seconds/3600, seconds%3600/60)
+
+	default:
+		return fmt.Sprintf("%dd %dh", seconds/86400, seconds%86400/3600)
+	}
+}
+
+func init() {
+	incidentCmd.AddCommand(incidentShowCmd)
+}
@@ -23,7 +23,6 @@ package cmd
 import (
 	"context"
 	"strings"
-	"time"
 
 	"github.com/PagerDuty/go-pagerduty"
 	"github.com/gonvenience/bunt"
@@ -31,11 +30,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var listAlertsCmdSettings struct {
-	id   string
-	from string
-	to   string
-}
+var listAlertsCmdSettings incidentSelection
 
 // currentShiftCmd represents the get command
 var listAlertsCmd = &cobra.Command{
@@ -44,44 +39,63 @@ var listAlertsCmd = &cobra.Command{
 	Short: "Lists all alerts",
 	Long:  `Lists all alerts in a specified time period`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := pd.CreatePagerDutyClient()
+		out := cmd.OutOrStdout()
+
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		filter, err := listAlertsCmdSettings.filter(cmd.Context(), client)
 		if err != nil {
 			return err
 		}
 
 		incidents, _, err := getRelevantIncidents(
 			cmd.Context(),
+			client,
 			listAlertsCmdSettings.id,
-			listAlertsCmdSettings.from,
-			listAlertsCmdSettings.to,
+			filter,
 		)
 
 		if err != nil {
 			return err
 		}
 
+		if isStructuredOutput() {
+			result, err := newIncidentOutputs(cmd.Context(), client, incidents)
+			if err != nil {
+				return err
+			}
+
+			return printStructured(out, result)
+		}
+
 		for i, incident := range incidents {
 
-			bunt.Printf("\n%d. *%s*\n", i+1, incident.Title)
+			bunt.Fprintf(out, "\n%d. *%s*\n", i+1, incident.Title)
 
 			if incident.Description != incident.Title {
-				bunt.Printf("   *Description:* \n")
+				bunt.Fprintf(out, "   *Description:* \n")
 				for _, line := range strings.Split(incident.Description, "\n") {
-					bunt.Printf("      %s\n", line)
+					bunt.Fprintf(out, "      %s\n", line)
 				}
 			}
 
-			bunt.Printf("   *Link:* CornflowerBlue{~%s~}\n", incident.HTMLURL)
+			bunt.Fprintf(out, "   *Link:* CornflowerBlue{~%s~}\n", incident.HTMLURL)
 
-			start := mustParsePagerDutyRFC3339ishTime(incident.CreatedAt)
+			bunt.Fprintf(out, "   *Time:* %s - %s",
+				pd.FormatTimestamp(incident.CreatedAt, "2006-01-02 15:04:05"),
+				pd.FormatTimestamp(incident.LastStatusChangeAt, "2006-01-02 15:04:05"),
+			)
 
-			end := mustParsePagerDutyRFC3339ishTime(incident.LastStatusChangeAt)
+			start, startErr := pd.ParseTimestamp(incident.CreatedAt)
+			end, endErr := pd.ParseTimestamp(incident.LastStatusChangeAt)
+			if startErr == nil && endErr == nil {
+				bunt.Fprintf(out, " (%s)", end.Sub(start))
+			}
 
-			bunt.Printf("   *Time:* %s - %s (%s)\n",
-				start.Local().Format("2006-01-02 15:04:05"),
-				end.Local().Format("2006-01-02 15:04:05"),
-				end.Sub(start),
-			)
+			bunt.Fprintln(out)
 
 			notes, err := client.ListIncidentNotesWithContext(cmd.Context(), incident.ID)
 			if err != nil {
@@ -89,28 +103,28 @@ var listAlertsCmd = &cobra.Command{
 			}
 
 			if len(notes) > 0 {
-				bunt.Printf("   *Notes:*\n")
+				bunt.Fprintf(out, "   *Notes:*\n")
 				for j := len(notes) - 1; j >= 0; j-- {
-					bunt.Printf("      %d. ", len(notes)-j)
+					bunt.Fprintf(out, "      %d. ", len(notes)-j)
 					for _, line := range strings.Split(notes[j].Content, "\n") {
-						bunt.Printf("%s\n         ", line)
+						bunt.Fprintf(out, "%s\n         ", line)
 					}
 
-					bunt.Printf("(by _%s_ at _%s_)\n",
+					bunt.Fprintf(out, "(by _%s_ at _%s_)\n",
 						lookUpNameByUserID(cmd.Context(), client, notes[j].User.ID),
-						formatNoteTime(notes[j].CreatedAt),
+						pd.FormatTimestamp(notes[j].CreatedAt, "2006-01-02 15:04:05"),
 					)
 				}
 			}
 		}
 
-		bunt.Println()
+		bunt.Fprintln(out)
 
 		return nil
 	},
 }
 
-func lookUpNameByUserID(ctx context.Context, client *pagerduty.Client, id string) string {
+func lookUpNameByUserID(ctx context.Context, client pd.Client, id string) string {
 	user, err := client.GetUserWithContext(ctx, id, pagerduty.GetUserOptions{})
 	if err != nil {
 		return "unknown"
@@ -118,27 +132,58 @@ func lookUpNameByUserID(ctx context.Context, client *pagerduty.Client, id string
 	return user.Name
 }
 
-func formatNoteTime(input string) string {
-	time, err := time.Parse("2006-01-02T15:04:05-07:00", input)
+func newIncidentOutput(ctx context.Context, client pd.Client, incident pagerduty.Incident) (incidentOutput, error) {
+	notes, err := client.ListIncidentNotesWithContext(ctx, incident.ID)
 	if err != nil {
-		return input
+		return incidentOutput{}, err
+	}
+
+	// timestamps that cannot be parsed are left empty
+	createdAt, _ := pd.ParseTimestamp(incident.CreatedAt)
+	lastStatusChangeAt, _ := pd.ParseTimestamp(incident.LastStatusChangeAt)
+
+	result := incidentOutput{
+		ID:                 incident.ID,
+		Number:             incident.IncidentNumber,
+		Title:              incident.Title,
+		Description:        incident.Description,
+		Status:             incident.Status,
+		Urgency:            incident.Urgency,
+		Service:            incident.Service.Summary,
+		URL:                incident.HTMLURL,
+		CreatedAt:          createdAt,
+		LastStatusChangeAt: lastStatusChangeAt,
+		Notes:              []noteOutput{},
+	}
+
+	for _, note := range notes {
+		createdAt, _ := pd.ParseTimestamp(note.CreatedAt)
+		result.Notes = append(result.Notes, noteOutput{
+			Author:    lookUpNameByUserID(ctx, client, note.User.ID),
+			Content:   note.Content,
+			CreatedAt: createdAt,
+		})
 	}
 
-	return time.Local().Format("2006-01-02 15:04:05")
+	return result, nil
 }
 
-func mustParsePagerDutyRFC3339ishTime(input string) time.Time {
-	time, err := time.Parse(time.RFC3339, input)
-	if err != nil {
-		panic(err)
+func newIncidentOutputs(ctx context.Context, client pd.Client, incidents []pagerduty.Incident) ([]incidentOutput, error) {
+	result := []incidentOutput{}
+	for _, incident := range incidents {
+		entry, err := newIncidentOutput(ctx, client, incident)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, entry)
 	}
-	return time
+
+	return result, nil
 }
 
 func init() {
 	rootCmd.AddCommand(listAlertsCmd)
 
-	listAlertsCmd.Flags().StringVar(&listAlertsCmdSettings.id, "id", "", "use custom ID")
-	listAlertsCmd.Flags().StringVar(&listAlertsCmdSettings.from, "from", "", "set startpoint of custom time period")
-	listAlertsCmd.Flags().StringVar(&listAlertsCmdSettings.to, "to", "", "set endpoint of custom time period")
+	listAlertsCmdSettings.addFlags(listAlertsCmd)
 }
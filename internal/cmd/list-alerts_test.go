@@ -0,0 +1,113 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestListAlertsOutput(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		expected   []string
+		unexpected []string
+		err        string
+	}{
+		{
+			name: "incidents of the current user with notes",
+			args: []string{"list-alerts"},
+			expected: []string{
+				"1. Disk full on db-1",
+				"Link: https://fake.pagerduty.com/incidents/PINC001",
+				"Time: 2022-11-07 08:00:00 - 2022-11-07 08:00:00 (0s)",
+				"2. Certificate expires",
+				"1. Renewal ordered",
+				"(by John Roe at 2022-11-07 10:10:00)",
+			},
+			unexpected: []string{"Queue backlog", "Other team"},
+		},
+		{
+			name:       "incidents of another user",
+			args:       []string{"list-alerts", "--id", "PUSER02"},
+			expected:   []string{"1. Queue backlog"},
+			unexpected: []string{"Disk full on db-1", "Certificate expires"},
+		},
+		{
+			name:       "only acknowledged incidents",
+			args:       []string{"list-alerts", "--involvement", "acknowledged"},
+			expected:   []string{"1. Certificate expires"},
+			unexpected: []string{"Disk full on db-1"},
+		},
+		{
+			name:       "only triggered incidents",
+			args:       []string{"list-alerts", "--status", "triggered"},
+			expected:   []string{"1. Disk full on db-1"},
+			unexpected: []string{"Certificate expires"},
+		},
+		{
+			name: "incidents as JSON",
+			args: []string{"list-alerts", "--output", "json"},
+			expected: []string{
+				`"id": "PINC001"`,
+				`"id": "PINC002"`,
+				`"author": "John Roe"`,
+				`"content": "Renewal ordered"`,
+			},
+			unexpected: []string{"PINC003", "PINC004"},
+		},
+		{
+			name: "user without teams",
+			args: []string{"list-alerts", "--id", "PUSER03"},
+			err:  "this PagerDuty-account is not part of any teams",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runCommand(t, newTestClient(), testConfig, "", tt.args...)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(out, expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, out)
+				}
+			}
+
+			for _, unexpected := range tt.unexpected {
+				if strings.Contains(out, unexpected) {
+					t.Errorf("expected output not to contain %q, got:\n%s", unexpected, out)
+				}
+			}
+		})
+	}
+}
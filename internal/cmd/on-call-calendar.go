@@ -0,0 +1,85 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gonvenience/bunt"
+	"github.com/homeport/pd/internal/pd"
+)
+
+// defaultOnCallDays is the length of the on-call time range if only its start is given
+const defaultOnCallDays = 14
+
+// renderOnCallCalendar prints one line per week of the time range, days with
+// on-call duty are highlighted
+func renderOnCallCalendar(out io.Writer, timeRanges []pd.TimeRange, from time.Time, to time.Time) {
+	from, to = pd.InTimezone(from), pd.InTimezone(to)
+
+	// start with the Monday of the first week
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, pd.Timezone())
+	day = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+
+	bunt.Fprintf(out, "\n        ")
+	for _, weekday := range []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"} {
+		bunt.Fprintf(out, " *%s* ", weekday)
+	}
+	fmt.Fprintln(out)
+
+	for day.Before(to) {
+		year, week := day.ISOWeek()
+		bunt.Fprintf(out, "%d-W%02d", year, week)
+
+		for i := 0; i < 7; i++ {
+			next := day.AddDate(0, 0, 1)
+
+			switch {
+			case !next.After(from) || !day.Before(to):
+				bunt.Fprintf(out, "     ")
+
+			case onCallDuring(timeRanges, day, next):
+				bunt.Fprintf(out, " Coral{*[%02d]*}", day.Day())
+
+			default:
+				bunt.Fprintf(out, "  DimGray{%02d} ", day.Day())
+			}
+
+			day = next
+		}
+
+		fmt.Fprintln(out)
+	}
+
+	bunt.Fprintf(out, "\nDays marked with Coral{*[  ]*} have on-call duty.\n\n")
+}
+
+func onCallDuring(timeRanges []pd.TimeRange, start time.Time, end time.Time) bool {
+	for _, timeRange := range timeRanges {
+		if timeRange.Start.Before(end) && start.Before(timeRange.End) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,103 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/gonvenience/bunt"
+	"github.com/gonvenience/wrap"
+	"github.com/homeport/pd/internal/pd"
+	"github.com/spf13/cobra"
+)
+
+// defaultExportDays is the length of the exported time range if none is given
+const defaultExportDays = 90
+
+var onCallExportCmdSettings struct {
+	format string
+	file   string
+}
+
+// onCallExportCmd represents the on-call export command
+var onCallExportCmd = &cobra.Command{
+	Use:   "export",
+	Args:  cobra.ExactArgs(0),
+	Short: "Export on-calls as calendar file",
+	Long: `Exports the upcoming on-calls of the current user as an iCalendar (.ics) file
+with one event per on-call window, by default the next 90 days are exported`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if onCallExportCmdSettings.format != "ics" {
+			return fmt.Errorf("unsupported export format %q, only ics is supported", onCallExportCmdSettings.format)
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		user, err := client.GetCurrentUserWithContext(cmd.Context(), pagerduty.GetCurrentUserOptions{})
+		if err != nil {
+			return wrap.Error(err, "it seems like the authtoken is not set correctly or outdated. Please update the authtoken in the .pd.yml file. If you don't know how to create your authtoken, this might help:\n https://support.pagerduty.com/docs/generating-api-keys#generating-a-personal-rest-api-key\n")
+		}
+
+		from, to, err := onCallTimeRange()
+		if err != nil {
+			return err
+		}
+
+		if from.IsZero() {
+			from, to = time.Now(), time.Now().AddDate(0, 0, defaultExportDays)
+		}
+
+		oncalls, err := pd.GetPagerDutyOnCalls(cmd.Context(), client, user, from.Format(time.RFC3339), to.Format(time.RFC3339))
+		if err != nil {
+			return err
+		}
+
+		if onCallExportCmdSettings.file == "" {
+			return pd.WriteICalendar(cmd.OutOrStdout(), user, oncalls, pd.TimeRange{Start: from, End: to})
+		}
+
+		file, err := os.Create(onCallExportCmdSettings.file)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		if err := pd.WriteICalendar(file, user, oncalls, pd.TimeRange{Start: from, End: to}); err != nil {
+			return err
+		}
+
+		bunt.Fprintf(cmd.ErrOrStderr(), "\nExported *%d* on-call windows to SkyBlue{%s}\n\n", len(oncalls), onCallExportCmdSettings.file)
+		return file.Close()
+	},
+}
+
+func init() {
+	onCallCmd.AddCommand(onCallExportCmd)
+
+	onCallExportCmd.Flags().StringVar(&onCallExportCmdSettings.format, "format", "ics", "export format (ics)")
+	onCallExportCmd.Flags().StringVar(&onCallExportCmdSettings.file, "file", "", "write to file instead of standard output")
+}
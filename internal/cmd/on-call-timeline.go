@@ -0,0 +1,141 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gonvenience/bunt"
+	"github.com/gonvenience/neat"
+	"github.com/homeport/pd/internal/pd"
+)
+
+// timelineWidth is the number of characters used for the timeline bars
+const timelineWidth = 28
+
+// renderOnCallTimeline prints the on-call windows in chronological order with
+// a bar that shows the position of each window within the time range, if no
+// time range is given, the span of all windows is used, which is extended to
+// the next days for permanent on-calls
+func renderOnCallTimeline(out io.Writer, windows []pd.OnCallWindow, from time.Time, to time.Time) error {
+	if from.IsZero() {
+		for _, window := range windows {
+			if !window.Start.IsZero() && (from.IsZero() || window.Start.Before(from)) {
+				from = window.Start
+			}
+
+			if window.End.After(to) {
+				to = window.End
+			}
+		}
+
+		if from.IsZero() {
+			from = time.Now()
+		}
+
+		if !to.After(from) {
+			to = from.AddDate(0, 0, defaultOnCallDays)
+		}
+	}
+
+	var table = [][]string{{
+		bunt.Sprint("*From*"),
+		bunt.Sprint("*To*"),
+		bunt.Sprint("*Level*"),
+		bunt.Sprint("*EscalationPolicy*"),
+		bunt.Sprint("*Timeline*"),
+		bunt.Sprint("*Link*"),
+	}}
+
+	for _, window := range windows {
+		table = append(table, []string{
+			formatOnCallTime(window.Start),
+			formatOnCallTime(window.End),
+			fmt.Sprint(window.EscalationLevel),
+			window.EscalationPolicy.Summary,
+			timelineBar(window.Bounded(from, to), from, to),
+			window.EscalationPolicy.HTMLURL,
+		})
+	}
+
+	content, err := neat.Table(table, neat.VertialBarSeparator())
+	if err != nil {
+		return err
+	}
+
+	neat.Box(
+		out,
+		bunt.Sprintf("*on-call timeline* from *%s* to *%s*",
+			pd.InTimezone(from).Format("2006-01-02 15:04"),
+			pd.InTimezone(to).Format("2006-01-02 15:04"),
+		),
+		strings.NewReader(content),
+		neat.HeadlineColor(bunt.LightSteelBlue),
+		neat.NoLineWrap(),
+	)
+
+	return nil
+}
+
+// formatOnCallTime returns the start or end of an on-call window, the open
+// start and end of permanent on-calls are shown as such
+func formatOnCallTime(t time.Time) string {
+	if t.IsZero() {
+		return "permanent"
+	}
+
+	return pd.InTimezone(t).Format("2006-01-02 15:04")
+}
+
+// timelineBar returns the time range as a bar of timelineWidth characters
+// scaled to the time range between from and to, ranges that are shorter than
+// one character are shown with at least one character
+func timelineBar(timeRange pd.TimeRange, from time.Time, to time.Time) string {
+	total := to.Sub(from)
+	if total <= 0 {
+		return strings.Repeat("█", timelineWidth)
+	}
+
+	position := func(t time.Time) float64 {
+		return float64(t.Sub(from)) / float64(total) * timelineWidth
+	}
+
+	start := int(math.Max(0, math.Floor(position(timeRange.Start))))
+	end := int(math.Min(timelineWidth, math.Ceil(position(timeRange.End))))
+
+	if start >= timelineWidth {
+		start = timelineWidth - 1
+	}
+
+	if end <= start {
+		end = start + 1
+	}
+
+	return bunt.Sprintf("DimGray{%s}Coral{%s}DimGray{%s}",
+		strings.Repeat("·", start),
+		strings.Repeat("█", end-start),
+		strings.Repeat("·", timelineWidth-end),
+	)
+}
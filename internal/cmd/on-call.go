@@ -21,9 +21,9 @@
 package cmd
 
 import (
-	"os"
-	"sort"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/PagerDuty/go-pagerduty"
 	"github.com/gonvenience/bunt"
@@ -33,71 +33,313 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var onCallCmdSettings struct {
+	from               string
+	to                 string
+	days               int
+	calendar           bool
+	users              []string
+	teams              []string
+	schedules          []string
+	escalationPolicies []string
+}
+
 // onCallCmd represents the onCall command
 var onCallCmd = &cobra.Command{
 	Use:   "on-call",
 	Short: "List on-calls for user",
-	Long:  `Check PagerDuty for all on-calls of the current user`,
+	Long: `Check PagerDuty for all on-calls of the current user, or list who is
+on-call for the selected users, teams, schedules, or escalation policies`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := pd.CreatePagerDutyClient()
+		out := cmd.OutOrStdout()
+
+		client, err := newClient()
 		if err != nil {
 			return err
 		}
 
+		if len(onCallCmdSettings.users) > 0 || len(onCallCmdSettings.teams) > 0 ||
+			len(onCallCmdSettings.schedules) > 0 || len(onCallCmdSettings.escalationPolicies) > 0 {
+			return listOnCallPersons(cmd, client)
+		}
+
 		user, err := client.GetCurrentUserWithContext(cmd.Context(), pagerduty.GetCurrentUserOptions{})
 		if err != nil {
 			return wrap.Error(err, "it seems like the authtoken is not set correctly or outdated. Please update the authtoken in the .pd.yml file. If you don't know how to create your authtoken, this might help:\n https://support.pagerduty.com/docs/generating-api-keys#generating-a-personal-rest-api-key\n")
 		}
 
-		oncalls, err := pd.GetPagerDutyOnCalls(cmd.Context(), client, user)
+		from, to, err := onCallTimeRange()
 		if err != nil {
-			neat.Box(
-				os.Stderr,
-				"Unexpected error occurred",
-				strings.NewReader(err.Error()),
-				neat.HeadlineColor(bunt.FireBrick),
-				neat.ContentColor(bunt.Coral),
-			)
+			return err
+		}
+
+		var since, until string
+		if !from.IsZero() {
+			since, until = from.Format(time.RFC3339), to.Format(time.RFC3339)
+		}
+
+		oncalls, err := pd.GetPagerDutyOnCalls(cmd.Context(), client, user, since, until)
+		if err != nil {
+			return err
 		}
 
-		switch len(oncalls) {
-		case 0:
-			bunt.Printf("\nYou are fine, there seem to be *no* on-call listed for your user.\nHave a nice day.\n\n")
+		if isStructuredOutput() {
+			return printStructured(out, newOnCallsOutput(oncalls))
+		}
+
+		if onCallCmdSettings.calendar {
+			if from.IsZero() {
+				from, to = time.Now(), time.Now().AddDate(0, 0, defaultOnCallDays)
+			}
+
+			var timeRanges []pd.TimeRange
+			for _, window := range oncalls {
+				timeRanges = append(timeRanges, window.Bounded(from, to))
+			}
+
+			renderOnCallCalendar(out, timeRanges, from, to)
+			return nil
+		}
+
+		switch {
+		case len(oncalls) == 0 && from.IsZero():
+			bunt.Fprintf(out, "\nYou are fine, there seem to be *no* on-call listed for your user.\nHave a nice day.\n\n")
+
+		case len(oncalls) == 0:
+			bunt.Fprintf(out, "\nYou are fine, there seem to be *no* on-call listed for your user between *%s* and *%s*.\n\n",
+				pd.InTimezone(from).Format("2006-01-02 15:04"),
+				pd.InTimezone(to).Format("2006-01-02 15:04"),
+			)
 
 		default:
-			bunt.Printf("\nIt turns out, you *are* on-call.\n\n")
-			for timeRange, escalationPolicies := range oncalls {
-				var table = [][]string{{bunt.Sprint("*EscalationPolicy*"), bunt.Sprint("*Link*")}}
-				for _, policy := range escalationPolicies {
-					table = append(table, []string{
-						policy.Summary,
-						policy.HTMLURL,
-					})
-				}
-
-				sort.Slice(table, func(i, j int) bool {
-					return strings.Compare(table[i][0], table[j][0]) < 0
-				})
-
-				out, err := neat.Table(table, neat.VertialBarSeparator())
-				if err != nil {
-					return err
-				}
-
-				neat.Box(
-					os.Stdout,
-					bunt.Sprintf("*on-call* from *%v* to *%v*", timeRange.Start.Local(), timeRange.End.Local()),
-					strings.NewReader(out),
-					neat.HeadlineColor(bunt.LightSteelBlue),
-					neat.NoLineWrap(),
+			if from.IsZero() {
+				bunt.Fprintf(out, "\nIt turns out, you *are* on-call.\n\n")
+			} else {
+				bunt.Fprintf(out, "\nYou are on-call *%d* times between *%s* and *%s*.\n\n", len(oncalls),
+					pd.InTimezone(from).Format("2006-01-02 15:04"),
+					pd.InTimezone(to).Format("2006-01-02 15:04"),
 				)
 			}
+
+			if err := renderOnCallTimeline(out, oncalls, from, to); err != nil {
+				return err
+			}
 		}
 
 		return nil
 	},
 }
 
+func newOnCallsOutput(oncalls []pd.OnCallWindow) []onCallOutput {
+	result := []onCallOutput{}
+	for _, window := range oncalls {
+		result = append(result, onCallOutput{
+			Start:            optionalTime(window.Start),
+			End:              optionalTime(window.End),
+			EscalationLevel:  window.EscalationLevel,
+			EscalationPolicy: newEscalationPolicyOutput(window.EscalationPolicy),
+		})
+	}
+
+	return result
+}
+
+// optionalTime returns nil for the zero time, which marks an open start or
+// end of a permanent on-call
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// onCallTimeRange returns the time range selected by the --from, --to, and
+// --days flags, or zero times if none of them is used
+func onCallTimeRange() (time.Time, time.Time, error) {
+	var from, to time.Time
+	if onCallCmdSettings.from == "" && onCallCmdSettings.to == "" && onCallCmdSettings.days == 0 {
+		return from, to, nil
+	}
+
+	days := onCallCmdSettings.days
+	if days == 0 {
+		days = defaultOnCallDays
+	}
+
+	from = time.Now()
+	to = from.AddDate(0, 0, days)
+	if onCallCmdSettings.from != "" {
+		timeRange, err := pd.ParseTimeExpression(onCallCmdSettings.from, time.Now())
+		if err != nil {
+			return from, to, err
+		}
+
+		// a range like next-week is used as a whole, unless the end is given
+		from, to = timeRange.Start, timeRange.Start.AddDate(0, 0, days)
+		if timeRange.End.After(timeRange.Start) && onCallCmdSettings.days == 0 {
+			to = timeRange.End
+		}
+	}
+
+	if onCallCmdSettings.to != "" {
+		timeRange, err := pd.ParseTimeExpression(onCallCmdSettings.to, time.Now())
+		if err != nil {
+			return from, to, err
+		}
+
+		to = timeRange.End
+	}
+
+	if !to.After(from) {
+		return from, to, fmt.Errorf("the end of the time range must be after its start")
+	}
+
+	return from, to, nil
+}
+
+func listOnCallPersons(cmd *cobra.Command, client pd.Client) error {
+	var (
+		ctx      = cmd.Context()
+		out      = cmd.OutOrStdout()
+		selector pd.OnCallSelector
+	)
+
+	for _, value := range onCallCmdSettings.users {
+		user, err := pd.ResolveUser(ctx, client, value)
+		if err != nil {
+			return err
+		}
+
+		selector.UserIDs = append(selector.UserIDs, user.ID)
+	}
+
+	for _, value := range onCallCmdSettings.teams {
+		team, err := pd.ResolveTeam(ctx, client, value)
+		if err != nil {
+			return err
+		}
+
+		policies, err := pd.ListAllEscalationPolicies(ctx, client, pagerduty.ListEscalationPoliciesOptions{TeamIDs: []string{team.ID}})
+		if err != nil {
+			return err
+		}
+
+		if len(policies) == 0 {
+			return fmt.Errorf("team %s has no escalation policies", team.Name)
+		}
+
+		for _, policy := range policies {
+			selector.EscalationPolicyIDs = append(selector.EscalationPolicyIDs, policy.ID)
+		}
+	}
+
+	for _, value := range onCallCmdSettings.schedules {
+		schedule, err := pd.ResolveSchedule(ctx, client, value)
+		if err != nil {
+			return err
+		}
+
+		selector.ScheduleIDs = append(selector.ScheduleIDs, schedule.ID)
+	}
+
+	for _, value := range onCallCmdSettings.escalationPolicies {
+		policy, err := pd.ResolveEscalationPolicy(ctx, client, value)
+		if err != nil {
+			return err
+		}
+
+		selector.EscalationPolicyIDs = append(selector.EscalationPolicyIDs, policy.ID)
+	}
+
+	persons, err := pd.GetOnCallPersons(ctx, client, selector)
+	if err != nil {
+		return err
+	}
+
+	if isStructuredOutput() {
+		result := []onCallPersonOutput{}
+		for _, person := range persons {
+			result = append(result, newOnCallPersonOutput(person))
+		}
+
+		return printStructured(out, result)
+	}
+
+	if len(persons) == 0 {
+		bunt.Fprintf(out, "\nThere seems to be *no* on-call for the selection.\n\n")
+		return nil
+	}
+
+	bunt.Fprintln(out)
+
+	var policyIDs []string
+	byPolicy := map[string][]pd.OnCallPerson{}
+	for _, person := range persons {
+		if _, found := byPolicy[person.EscalationPolicy.ID]; !found {
+			policyIDs = append(policyIDs, person.EscalationPolicy.ID)
+		}
+
+		byPolicy[person.EscalationPolicy.ID] = append(byPolicy[person.EscalationPolicy.ID], person)
+	}
+
+	for _, policyID := range policyIDs {
+		var table = [][]string{{bunt.Sprint("*Level*"), bunt.Sprint("*User*"), bunt.Sprint("*Until*"), bunt.Sprint("*Contact*")}}
+		for _, person := range byPolicy[policyID] {
+			until := "permanent"
+			if end, err := pd.ParseTimestamp(person.End); err == nil {
+				until = pd.InTimezone(end).Format("2006-01-02 15:04")
+			}
+
+			table = append(table, []string{
+				fmt.Sprint(person.EscalationLevel),
+				person.User.Summary,
+				until,
+				formatContactMethods(person.ContactMethods),
+			})
+		}
+
+		content, err := neat.Table(table, neat.VertialBarSeparator())
+		if err != nil {
+			return err
+		}
+
+		policy := byPolicy[policyID][0].EscalationPolicy
+		neat.Box(
+			out,
+			bunt.Sprintf("*on-call* for *%s* (CornflowerBlue{~%s~})", policy.Summary, policy.HTMLURL),
+			strings.NewReader(content),
+			neat.HeadlineColor(bunt.LightSteelBlue),
+			neat.NoLineWrap(),
+		)
+	}
+
+	return nil
+}
+
+// formatContactMethods returns the contact methods in a compact form like "phone: +1 555 1234"
+func formatContactMethods(methods []pagerduty.ContactMethod) string {
+	var result []string
+	for _, method := range methods {
+		result = append(result, fmt.Sprintf("%s: %s", contactMethodType(method), method.Address))
+	}
+
+	return strings.Join(result, ", ")
+}
+
+func contactMethodType(method pagerduty.ContactMethod) string {
+	return strings.TrimSuffix(strings.TrimSuffix(method.Type, "_reference"), "_contact_method")
+}
+
 func init() {
 	rootCmd.AddCommand(onCallCmd)
+
+	onCallCmd.PersistentFlags().StringVar(&onCallCmdSettings.from, "from", "", "list on-calls starting from this time (date, RFC3339 time, or expression like next-week)")
+	onCallCmd.PersistentFlags().StringVar(&onCallCmdSettings.to, "to", "", "list on-calls until this time (same formats as --from)")
+	onCallCmd.PersistentFlags().IntVar(&onCallCmdSettings.days, "days", 0, "list on-calls of the upcoming number of days")
+	onCallCmd.Flags().BoolVar(&onCallCmdSettings.calendar, "calendar", false, "show a week calendar of the days with on-call duty")
+	onCallCmd.Flags().StringSliceVar(&onCallCmdSettings.users, "user", nil, "list on-calls of user (ID, email, name, or me)")
+	onCallCmd.Flags().StringSliceVar(&onCallCmdSettings.teams, "team", nil, "list on-calls of the escalation policies of team (ID or name)")
+	onCallCmd.Flags().StringSliceVar(&onCallCmdSettings.schedules, "schedule", nil, "list on-calls of schedule (ID or name)")
+	onCallCmd.Flags().StringSliceVar(&onCallCmdSettings.escalationPolicies, "escalation-policy", nil, "list on-calls of escalation policy (ID or name)")
 }
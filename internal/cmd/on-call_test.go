@@ -0,0 +1,95 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+func TestOnCallOutput(t *testing.T) {
+	now := time.Now().UTC()
+	policy := pagerduty.EscalationPolicy{APIObject: pagerduty.APIObject{ID: "PPOLI01", Summary: "Platform"}, Name: "Platform"}
+	jane := pagerduty.User{APIObject: pagerduty.APIObject{ID: "PUSER01", Summary: "Jane Doe"}}
+
+	tests := []struct {
+		name     string
+		oncalls  []pagerduty.OnCall
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "no on-calls",
+			args:     []string{"on-call"},
+			expected: []string{"You are fine, there seem to be no on-call listed for your user."},
+		},
+		{
+			name: "active on-call",
+			oncalls: []pagerduty.OnCall{{
+				User:             jane,
+				EscalationPolicy: policy,
+				EscalationLevel:  1,
+				Start:            now.Add(-time.Hour).Format(time.RFC3339),
+				End:              now.Add(time.Hour).Format(time.RFC3339),
+			}},
+			args:     []string{"on-call"},
+			expected: []string{"It turns out, you are on-call.", "Platform"},
+		},
+		{
+			name:     "permanent on-call as JSON",
+			oncalls:  []pagerduty.OnCall{{User: jane, EscalationPolicy: policy, EscalationLevel: 2}},
+			args:     []string{"on-call", "--output", "json"},
+			expected: []string{`"start": null`, `"end": null`, `"escalation_level": 2`, `"name": "Platform"`},
+		},
+		{
+			name:     "on-call persons of a team",
+			oncalls:  []pagerduty.OnCall{{User: jane, EscalationPolicy: policy, EscalationLevel: 1}},
+			args:     []string{"on-call", "--team", "Platform"},
+			expected: []string{"on-call for Platform", "Jane Doe", "permanent"},
+		},
+		{
+			name:     "no on-call persons for a user",
+			args:     []string{"on-call", "--user", "john@example.com"},
+			expected: []string{"There seems to be no on-call for the selection."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient()
+			client.OnCalls = tt.oncalls
+
+			out, err := runCommand(t, client, testConfig, "", tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(out, expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, out)
+				}
+			}
+		})
+	}
+}
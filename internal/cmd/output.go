@@ -0,0 +1,351 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/homeport/pd/internal/pd"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported values of the --output flag
+const (
+	outputHuman = "human"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// onCallOutput is the structured output of an on-call window
+type onCallOutput struct {
+	Start            *time.Time             `json:"start" yaml:"start"`
+	End              *time.Time             `json:"end" yaml:"end"`
+	EscalationLevel  uint                   `json:"escalation_level" yaml:"escalation_level"`
+	EscalationPolicy escalationPolicyOutput `json:"escalation_policy" yaml:"escalation_policy"`
+}
+
+// escalationPolicyOutput is the structured output of an escalation policy
+type escalationPolicyOutput struct {
+	ID   string `json:"id" yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url" yaml:"url"`
+}
+
+// onCallPersonOutput is the structured output of an on-call user
+type onCallPersonOutput struct {
+	User             userOutput             `json:"user" yaml:"user"`
+	EscalationPolicy escalationPolicyOutput `json:"escalation_policy" yaml:"escalation_policy"`
+	EscalationLevel  uint                   `json:"escalation_level" yaml:"escalation_level"`
+	Schedule         string                 `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+	Start            string                 `json:"start,omitempty" yaml:"start,omitempty"`
+	End              string                 `json:"end,omitempty" yaml:"end,omitempty"`
+	ContactMethods   []contactMethodOutput  `json:"contact_methods" yaml:"contact_methods"`
+}
+
+// userOutput is the structured output of a user
+type userOutput struct {
+	ID   string `json:"id" yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+}
+
+// contactMethodOutput is the structured output of a contact method
+type contactMethodOutput struct {
+	Type    string `json:"type" yaml:"type"`
+	Label   string `json:"label" yaml:"label"`
+	Address string `json:"address" yaml:"address"`
+}
+
+// incidentOutput is the structured output of an incident including its notes
+type incidentOutput struct {
+	ID                 string       `json:"id" yaml:"id"`
+	Number             uint         `json:"number" yaml:"number"`
+	Title              string       `json:"title" yaml:"title"`
+	Description        string       `json:"description" yaml:"description"`
+	Status             string       `json:"status" yaml:"status"`
+	Urgency            string       `json:"urgency" yaml:"urgency"`
+	Service            string       `json:"service" yaml:"service"`
+	URL                string       `json:"url" yaml:"url"`
+	CreatedAt          time.Time    `json:"created_at" yaml:"created_at"`
+	LastStatusChangeAt time.Time    `json:"last_status_change_at" yaml:"last_status_change_at"`
+	Notes              []noteOutput `json:"notes" yaml:"notes"`
+}
+
+// noteOutput is the structured output of an incident note
+type noteOutput struct {
+	Author    string    `json:"author" yaml:"author"`
+	Content   string    `json:"content" yaml:"content"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+}
+
+// shiftOutput is the structured output of a configured shift
+type shiftOutput struct {
+	Name     string `json:"name" yaml:"name"`
+	Start    string `json:"start" yaml:"start"`
+	End      string `json:"end" yaml:"end"`
+	TimeZone string `json:"tz" yaml:"tz"`
+}
+
+// shiftStatusOutput is the structured output of the current shift status
+type shiftStatusOutput struct {
+	Current       *shiftOutput `json:"current" yaml:"current"`
+	Next          *shiftOutput `json:"next" yaml:"next"`
+	Handover      *time.Time   `json:"handover" yaml:"handover"`
+	Own           *shiftOutput `json:"own" yaml:"own"`
+	OwnShiftStart *time.Time   `json:"own_shift_start" yaml:"own_shift_start"`
+}
+
+// shiftReportOutput is the structured output of a shift report
+type shiftReportOutput struct {
+	Username      string           `json:"username" yaml:"username"`
+	Date          string           `json:"date" yaml:"date"`
+	OwnShiftStart time.Time        `json:"own_shift_start" yaml:"own_shift_start"`
+	OwnShiftEnd   time.Time        `json:"own_shift_end" yaml:"own_shift_end"`
+	Incidents     []incidentOutput `json:"incidents" yaml:"incidents"`
+	Report        string           `json:"report" yaml:"report"`
+}
+
+// handoverOutput is the structured output of a handover report
+type handoverOutput struct {
+	Username             string           `json:"username" yaml:"username"`
+	Shift                string           `json:"shift" yaml:"shift"`
+	Start                time.Time        `json:"start" yaml:"start"`
+	End                  time.Time        `json:"end" yaml:"end"`
+	Incidents            []incidentOutput `json:"incidents" yaml:"incidents"`
+	CarriedOverIncidents []incidentOutput `json:"carried_over_incidents" yaml:"carried_over_incidents"`
+	Report               string           `json:"report" yaml:"report"`
+}
+
+// shiftIssueOutput is the structured output of a shift configuration issue
+type shiftIssueOutput struct {
+	Line     int    `json:"line" yaml:"line"`
+	Severity string `json:"severity" yaml:"severity"`
+	Message  string `json:"message" yaml:"message"`
+}
+
+// incidentActionOutput is the structured output of an action on an incident
+type incidentActionOutput struct {
+	ID     string `json:"id" yaml:"id"`
+	Number uint   `json:"number,omitempty" yaml:"number,omitempty"`
+	Title  string `json:"title,omitempty" yaml:"title,omitempty"`
+	Action string `json:"action" yaml:"action"`
+	Status string `json:"status,omitempty" yaml:"status,omitempty"`
+	Error  string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// incidentTimelineOutput is the structured output of an incident with its
+// timeline, the durations are in seconds and missing if they do not apply
+type incidentTimelineOutput struct {
+	ID                       string                `json:"id" yaml:"id"`
+	Number                   uint                  `json:"number" yaml:"number"`
+	Title                    string                `json:"title" yaml:"title"`
+	Description              string                `json:"description" yaml:"description"`
+	Status                   string                `json:"status" yaml:"status"`
+	Urgency                  string                `json:"urgency" yaml:"urgency"`
+	Service                  string                `json:"service" yaml:"service"`
+	URL                      string                `json:"url" yaml:"url"`
+	TriggeredAt              time.Time             `json:"triggered_at" yaml:"triggered_at"`
+	TimeToAcknowledgeSeconds *int64                `json:"time_to_acknowledge_seconds,omitempty" yaml:"time_to_acknowledge_seconds,omitempty"`
+	TimeToResolveSeconds     *int64                `json:"time_to_resolve_seconds,omitempty" yaml:"time_to_resolve_seconds,omitempty"`
+	Timeline                 []timelineEventOutput `json:"timeline" yaml:"timeline"`
+}
+
+// timelineEventOutput is the structured output of a step in an incident
+// timeline, the time since the previous step is in seconds and missing for
+// the first step
+type timelineEventOutput struct {
+	Time                 time.Time `json:"time" yaml:"time"`
+	SincePreviousSeconds *int64    `json:"since_previous_seconds,omitempty" yaml:"since_previous_seconds,omitempty"`
+	Type                 string    `json:"type" yaml:"type"`
+	Actor                string    `json:"actor" yaml:"actor"`
+	Summary              string    `json:"summary" yaml:"summary"`
+}
+
+// incidentChangeOutput is the structured output of a change reported by watch
+type incidentChangeOutput struct {
+	timelineEventOutput `yaml:",inline"`
+	Incident            incidentReferenceOutput `json:"incident" yaml:"incident"`
+}
+
+// incidentReferenceOutput is the structured output of a reference to an incident
+type incidentReferenceOutput struct {
+	ID    string `json:"id" yaml:"id"`
+	Title string `json:"title" yaml:"title"`
+	URL   string `json:"url" yaml:"url"`
+}
+
+// dashboardOutput is the structured output of the dashboard
+type dashboardOutput struct {
+	Shift     shiftStatusOutput `json:"shift" yaml:"shift"`
+	OnCalls   []onCallOutput    `json:"on_calls" yaml:"on_calls"`
+	Incidents []incidentOutput  `json:"incidents" yaml:"incidents"`
+}
+
+// validateOutputFormat checks the value of the --output flag
+func validateOutputFormat(format string) error {
+	switch format {
+	case outputHuman, outputJSON, outputYAML:
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported output format %q, use one of: %s, %s, %s", format, outputHuman, outputJSON, outputYAML)
+	}
+}
+
+// isStructuredOutput returns whether a machine-readable output was requested
+func isStructuredOutput() bool {
+	return rootCmdSettings.output == outputJSON || rootCmdSettings.output == outputYAML
+}
+
+// printStructured writes the value in the requested machine-readable format
+func printStructured(out io.Writer, value interface{}) error {
+	switch rootCmdSettings.output {
+	case outputJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+
+	case outputYAML:
+		encoder := yaml.NewEncoder(out)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			return err
+		}
+		return encoder.Close()
+
+	default:
+		return fmt.Errorf("unsupported output format %q", rootCmdSettings.output)
+	}
+}
+
+// printStructuredStream writes the value as one entry of a stream, which is
+// a line of JSON or a YAML document
+func printStructuredStream(out io.Writer, value interface{}) error {
+	switch rootCmdSettings.output {
+	case outputJSON:
+		return json.NewEncoder(out).Encode(value)
+
+	case outputYAML:
+		if _, err := fmt.Fprintln(out, "---"); err != nil {
+			return err
+		}
+
+		return printStructured(out, value)
+
+	default:
+		return fmt.Errorf("unsupported output format %q", rootCmdSettings.output)
+	}
+}
+
+func newShiftOutput(shift pd.Shift) *shiftOutput {
+	if shift.Name == "" {
+		return nil
+	}
+
+	var timeZone string
+	if shift.Location != nil {
+		timeZone = shift.Location.String()
+	}
+
+	return &shiftOutput{
+		Name:     shift.Name,
+		Start:    shift.Start.String(),
+		End:      shift.End.String(),
+		TimeZone: timeZone,
+	}
+}
+
+func newOnCallPersonOutput(person pd.OnCallPerson) onCallPersonOutput {
+	result := onCallPersonOutput{
+		User:             userOutput{ID: person.User.ID, Name: person.User.Summary},
+		EscalationPolicy: newEscalationPolicyOutput(person.EscalationPolicy),
+		EscalationLevel:  person.EscalationLevel,
+		Schedule:         person.Schedule.Summary,
+		Start:            person.Start,
+		End:              person.End,
+		ContactMethods:   []contactMethodOutput{},
+	}
+
+	for _, method := range person.ContactMethods {
+		result.ContactMethods = append(result.ContactMethods, contactMethodOutput{
+			Type:    contactMethodType(method),
+			Label:   method.Label,
+			Address: method.Address,
+		})
+	}
+
+	return result
+}
+
+func newIncidentTimelineOutput(timeline *pd.IncidentTimeline) incidentTimelineOutput {
+	incident := timeline.Incident
+	result := incidentTimelineOutput{
+		ID:          incident.ID,
+		Number:      incident.IncidentNumber,
+		Title:       incident.Title,
+		Description: incident.Description,
+		Status:      incident.Status,
+		Urgency:     incident.Urgency,
+		Service:     incident.Service.Summary,
+		URL:         incident.HTMLURL,
+		TriggeredAt: timeline.Triggered,
+		Timeline:    []timelineEventOutput{},
+	}
+
+	if d, ok := timeline.TimeToAcknowledge(); ok {
+		seconds := int64(d.Seconds())
+		result.TimeToAcknowledgeSeconds = &seconds
+	}
+
+	if d, ok := timeline.TimeToResolve(); ok {
+		seconds := int64(d.Seconds())
+		result.TimeToResolveSeconds = &seconds
+	}
+
+	for i, event := range timeline.Events {
+		entry := timelineEventOutput{
+			Time:    event.Time,
+			Type:    event.Type,
+			Actor:   event.Actor,
+			Summary: event.Summary,
+		}
+
+		if i > 0 {
+			seconds := int64(event.Time.Sub(timeline.Events[i-1].Time).Seconds())
+			entry.SincePreviousSeconds = &seconds
+		}
+
+		result.Timeline = append(result.Timeline, entry)
+	}
+
+	return result
+}
+
+func newEscalationPolicyOutput(policy pagerduty.EscalationPolicy) escalationPolicyOutput {
+	return escalationPolicyOutput{
+		ID:   policy.ID,
+		Name: policy.Summary,
+		URL:  policy.HTMLURL,
+	}
+}
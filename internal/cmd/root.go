@@ -21,12 +21,24 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/homeport/pd/internal/pd"
 	"github.com/spf13/cobra"
 )
 
+var rootCmdSettings struct {
+	profile  string
+	output   string
+	limit    int
+	verbose  bool
+	timezone string
+}
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "pd",
@@ -34,13 +46,41 @@ var rootCmd = &cobra.Command{
 	Long: `The PagerDuty tasks helper tool is command line interface program to assist
 with simple questions that would otherwise require to open the browser to
 search through the PagerDuty website to find the answer.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		pd.SelectProfile(rootCmdSettings.profile)
+		if err := pd.SetTimezone(rootCmdSettings.timezone); err != nil {
+			return err
+		}
+
+		pd.SetLimit(rootCmdSettings.limit)
+		pd.SetWarningOutput(cmd.ErrOrStderr())
+		if rootCmdSettings.verbose {
+			pd.SetVerboseOutput(cmd.ErrOrStderr())
+		}
+
+		return validateOutputFormat(rootCmdSettings.output)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// An interrupt cancels the context of the command, so that long running
+// commands like watch can stop cleanly.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+
+	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&rootCmdSettings.profile, "profile", "", "use named profile of the .pd.yml file (defaults to $"+pd.ProfileEnvVar+" or default-profile)")
+	rootCmd.PersistentFlags().StringVarP(&rootCmdSettings.output, "output", "o", outputHuman, "output format: human, json, or yaml")
+	rootCmd.PersistentFlags().IntVar(&rootCmdSettings.limit, "limit", pd.DefaultLimit, "maximum number of entries to fetch per listing from PagerDuty")
+	rootCmd.PersistentFlags().BoolVarP(&rootCmdSettings.verbose, "verbose", "v", false, "report retried PagerDuty API requests")
+	rootCmd.PersistentFlags().StringVar(&rootCmdSettings.timezone, "timezone", "", "time zone used to display times and to interpret dates, like Europe/Berlin (defaults to the system time zone)")
+}
@@ -0,0 +1,181 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/homeport/pd/internal/pd"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const testConfig = `authtoken: fake
+own-shift: EMEA
+shift-times:
+- name: EMEA
+  start: "08:00"
+  end: "20:00"
+- name: AMER
+  start: "20:00"
+  end: "08:00"
+templates:
+  report: |-
+    Report of {{ .Username }} for {{ .Date }}:
+    {{ range .Incidents }}- #{{ .IncidentNumber }} {{ .Title }}
+    {{ end }}
+`
+
+// runCommand runs the pd command line with the arguments against the given
+// client and a .pd.yml file with the given content in a temporary home
+// directory, it returns everything written to standard output and error
+func runCommand(t *testing.T, client pd.Client, config string, stdin string, args ...string) (string, error) {
+	t.Helper()
+
+	home := t.TempDir()
+	if err := os.WriteFile(filepath.Join(home, ".pd.yml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HOME", home)
+	t.Setenv(pd.ProfileEnvVar, "")
+
+	original := newClient
+	newClient = func() (pd.Client, error) { return client, nil }
+	t.Cleanup(func() {
+		newClient = original
+		resetCommand(rootCmd)
+	})
+
+	resetCommand(rootCmd)
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetIn(strings.NewReader(stdin))
+	rootCmd.SetArgs(append(args, "--timezone", "UTC"))
+
+	err := rootCmd.ExecuteContext(context.Background())
+	return out.String(), err
+}
+
+// resetCommand sets all flags of the command and its sub-commands back to
+// their defaults, since cobra keeps the flag values between executions
+func resetCommand(cmd *cobra.Command) {
+	reset := func(flag *pflag.Flag) {
+		if value, ok := flag.Value.(pflag.SliceValue); ok {
+			_ = value.Replace(nil)
+		} else {
+			_ = flag.Value.Set(flag.DefValue)
+		}
+
+		flag.Changed = false
+	}
+
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	cmd.SetContext(nil)
+
+	for _, sub := range cmd.Commands() {
+		resetCommand(sub)
+	}
+}
+
+// newTestClient returns a fake client with the current user Jane of the
+// Platform team, who was involved in the first two of four incidents
+func newTestClient() *pd.FakeClient {
+	platform := pagerduty.Team{APIObject: pagerduty.APIObject{ID: "PTEAM01", Summary: "Platform"}, Name: "Platform"}
+	jane := pagerduty.User{
+		APIObject: pagerduty.APIObject{ID: "PUSER01", Summary: "Jane Doe"},
+		Name:      "Jane Doe",
+		Email:     "jane@example.com",
+		Teams:     []pagerduty.Team{platform},
+	}
+
+	policy := pagerduty.EscalationPolicy{
+		APIObject: pagerduty.APIObject{ID: "PPOLI01", Summary: "Platform", HTMLURL: "https://fake.pagerduty.com/escalation_policies/PPOLI01"},
+		Name:      "Platform",
+		Teams:     []pagerduty.APIReference{{ID: "PTEAM01", Type: "team_reference"}},
+	}
+
+	incident := func(id string, number uint, title string, status string, createdAt string, teamID string) pagerduty.Incident {
+		return pagerduty.Incident{
+			APIObject:          pagerduty.APIObject{ID: id, HTMLURL: "https://fake.pagerduty.com/incidents/" + id},
+			IncidentNumber:     number,
+			Title:              title,
+			Description:        title,
+			Status:             status,
+			Urgency:            "high",
+			CreatedAt:          createdAt,
+			LastStatusChangeAt: createdAt,
+			Service:            pagerduty.APIObject{ID: "PSERV01", Summary: "Checkout API"},
+			EscalationPolicy:   policy.APIObject,
+			Teams:              []pagerduty.APIObject{{ID: teamID}},
+		}
+	}
+
+	return &pd.FakeClient{
+		CurrentUser: &jane,
+		Users: []pagerduty.User{
+			jane,
+			{APIObject: pagerduty.APIObject{ID: "PUSER02", Summary: "John Roe"}, Name: "John Roe", Email: "john@example.com", Teams: []pagerduty.Team{platform}},
+			{APIObject: pagerduty.APIObject{ID: "PUSER03", Summary: "Johanna Poe"}, Name: "Johanna Poe", Email: "johanna@example.com"},
+		},
+		Teams:    []pagerduty.Team{platform},
+		Policies: []pagerduty.EscalationPolicy{policy},
+		Services: []pagerduty.Service{
+			{APIObject: pagerduty.APIObject{ID: "PSERV01", Summary: "Checkout API"}, Name: "Checkout API", EscalationPolicy: policy, Teams: []pagerduty.Team{platform}},
+			{APIObject: pagerduty.APIObject{ID: "PSERV02", Summary: "Checkout Worker"}, Name: "Checkout Worker", EscalationPolicy: policy, Teams: []pagerduty.Team{platform}},
+		},
+		Incidents: []pagerduty.Incident{
+			incident("PINC001", 1, "Disk full on db-1", "triggered", "2022-11-07T08:00:00Z", "PTEAM01"),
+			incident("PINC002", 2, "Certificate expires", "acknowledged", "2022-11-07T10:00:00Z", "PTEAM01"),
+			incident("PINC003", 3, "Queue backlog", "resolved", "2022-11-07T12:00:00Z", "PTEAM01"),
+			incident("PINC004", 4, "Other team", "triggered", "2022-11-07T14:00:00Z", "PTEAM02"),
+		},
+		LogEntries: map[string][]pagerduty.LogEntry{
+			"PINC001": {testLogEntry("trigger_log_entry", "2022-11-07T08:00:00Z", "PUSER01")},
+			"PINC002": {testLogEntry("acknowledge_log_entry", "2022-11-07T10:05:00Z", "PUSER01")},
+			"PINC003": {testLogEntry("resolve_log_entry", "2022-11-07T12:30:00Z", "PUSER02")},
+			"PINC004": {testLogEntry("trigger_log_entry", "2022-11-07T14:00:00Z", "PUSER01")},
+		},
+		Notes: map[string][]pagerduty.IncidentNote{
+			"PINC002": {{ID: "PNOTE01", User: pagerduty.APIObject{ID: "PUSER02"}, Content: "Renewal ordered", CreatedAt: "2022-11-07T10:10:00Z"}},
+		},
+	}
+}
+
+// testLogEntry returns a log entry of the kind, in which the user is the
+// agent and the assignee
+func testLogEntry(kind string, createdAt string, userID string) pagerduty.LogEntry {
+	return pagerduty.LogEntry{CommonLogEntryField: pagerduty.CommonLogEntryField{
+		APIObject: pagerduty.APIObject{Type: kind},
+		CreatedAt: createdAt,
+		Agent:     pagerduty.Agent{ID: userID},
+		Assignees: []pagerduty.APIObject{{ID: userID}},
+	}}
+}
@@ -38,6 +38,7 @@ var setRegionCmd = &cobra.Command{
 	Short: "Sets own shift in .pd.yml file",
 	Long:  `Sets own shift in .pd.yml file depending on the argument/your time zone.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
 
 		shifts, _, err := pd.LoadShifts()
 		if err != nil {
@@ -53,31 +54,40 @@ var setRegionCmd = &cobra.Command{
 			if err != nil {
 				return err
 			}
-			bunt.Printf("\nYou've been added to SkyBlue{%s} because of your timezone.\n", ownShift.Name)
-			bunt.Printf("If this is not the right shift, please run the 'LightSlateGray{%s}' command followed by your shift name.\n\n", cmdName)
+			if isStructuredOutput() {
+				return printStructured(out, map[string]string{"own_shift": ownShift.Name})
+			}
+			bunt.Fprintf(out, "\nYou've been added to SkyBlue{%s} because of your timezone.\n", ownShift.Name)
+			bunt.Fprintf(out, "If this is not the right shift, please run the 'LightSlateGray{%s}' command followed by your shift name.\n\n", cmdName)
 		} else {
 			pos := -1
 			for i, shift := range shifts {
-				if shift.Name[5:] == args[0] || shift.Name == args[0] {
+				if shortShiftName(shift.Name) == args[0] || shift.Name == args[0] {
 					pos = i
 				}
 			}
 			if pos == -1 {
 				shiftNames := []string{}
 				for i, shift := range shifts {
-					shiftNames = append(shiftNames, shift.Name[5:])
+					shiftNames = append(shiftNames, shortShiftName(shift.Name))
 					if i != len(shifts)-1 {
 						shiftNames[i] += " /"
 					}
 				}
-				bunt.Printf("\nYour input was invalid. Please run the 'LightSlateGray{%s}' command followed by one of these:  %s\n\n", cmdName, strings.Trim(fmt.Sprint(shiftNames), "[]"))
+				if isStructuredOutput() {
+					return fmt.Errorf("unknown shift %q", args[0])
+				}
+				bunt.Fprintf(out, "\nYour input was invalid. Please run the 'LightSlateGray{%s}' command followed by one of these:  %s\n\n", cmdName, strings.Trim(fmt.Sprint(shiftNames), "[]"))
 				return nil
 			}
 			err := pd.ChangeYAMLFile("own-shift", shifts[pos].Name)
 			if err != nil {
 				return err
 			}
-			bunt.Printf("\nYou've been added to SkyBlue{%s}\n\n", shifts[pos].Name)
+			if isStructuredOutput() {
+				return printStructured(out, map[string]string{"own_shift": shifts[pos].Name})
+			}
+			bunt.Fprintf(out, "\nYou've been added to SkyBlue{%s}\n\n", shifts[pos].Name)
 		}
 
 		return nil
@@ -87,3 +97,9 @@ var setRegionCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(setRegionCmd)
 }
+
+// shortShiftName returns the shift name without the "Team " prefix of the
+// example configuration, names without it are returned unchanged
+func shortShiftName(name string) string {
+	return strings.TrimPrefix(name, "Team ")
+}
@@ -21,12 +21,14 @@
 package cmd
 
 import (
+	"bytes"
 	"html/template"
-	"os"
 	"strings"
+	"time"
 
 	"github.com/PagerDuty/go-pagerduty"
 	"github.com/gonvenience/bunt"
+	"github.com/gonvenience/wrap"
 	"github.com/homeport/pd/internal/pd"
 	"github.com/spf13/cobra"
 )
@@ -41,6 +43,7 @@ var shiftReportCmdSettings struct {
 	id           string
 	templateName string
 	date         string
+	involvement  []string
 }
 
 // onCallCmd represents the onCall command
@@ -50,16 +53,46 @@ var shiftReportCmd = &cobra.Command{
 	Short: "Creates shift report",
 	Long:  `Creates a shift report based on the provided template`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+
 		if shiftReportCmdSettings.id == "" || shiftReportCmdSettings.templateName == "" || shiftReportCmdSettings.date == "" {
-			bunt.Println("\nPlease use all flags!\n")
+			bunt.Fprintln(out, "\nPlease use all flags!\n")
 			return nil
 		}
 
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		involvements, err := pd.ParseInvolvements(shiftReportCmdSettings.involvement)
+		if err != nil {
+			return err
+		}
+
+		timeRange, err := pd.ParseTimeExpression(shiftReportCmdSettings.date, time.Now())
+		if err != nil {
+			return err
+		}
+
+		// an instant selects the whole day it is on
+		if timeRange.Start.Equal(timeRange.End) {
+			start := pd.InTimezone(timeRange.Start)
+			timeRange.Start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, pd.Timezone())
+			timeRange.End = timeRange.Start.AddDate(0, 0, 1)
+		}
+
+		date := pd.InTimezone(timeRange.Start).Format("2006-01-02")
+
 		incidents, username, err := getRelevantIncidents(
 			cmd.Context(),
+			client,
 			shiftReportCmdSettings.id,
-			shiftReportCmdSettings.date+"T00:00:01Z",
-			shiftReportCmdSettings.date+"T23:59:59Z",
+			incidentFilter{
+				from:         timeRange.Start.Format(time.RFC3339),
+				to:           timeRange.End.Format(time.RFC3339),
+				involvements: involvements,
+			},
 		)
 
 		if err != nil {
@@ -76,35 +109,80 @@ var shiftReportCmd = &cobra.Command{
 			return err
 		}
 
-		funcMap := map[string]interface{}{
-			"makeSlice":                    makeSlice,
-			"getCategoryMatchingIncidents": getCategoryMatchingIncidents,
+		var (
+			ownShift        pd.TimeRange
+			startOfOwnShift string
+			endOfOwnShift   string
+		)
+
+		if shiftPos != -1 {
+			start := pd.InTimezone(timeRange.Start)
+			ownShift = shifts[shiftPos].On(start.Year(), start.Month(), start.Day())
+			startOfOwnShift = shifts[shiftPos].Start.String()
+			endOfOwnShift = shifts[shiftPos].End.String()
 		}
-		temp := template.New("template").Funcs(template.FuncMap(funcMap))
 
-		temp, _ = temp.Parse(string(data))
+		temp, err := newReportTemplate(data)
+		if err != nil {
+			return err
+		}
 
 		input := struct {
 			Username        string
 			Date            string
 			StartOfOwnShift string
 			EndOfOwnShift   string
+			OwnShift        pd.TimeRange
 			Incidents       []pagerduty.Incident
 		}{
 			Username:        username,
-			Date:            shiftReportCmdSettings.date,
-			StartOfOwnShift: convertTimeIntToString(int(shifts[shiftPos].Start)),
-			EndOfOwnShift:   convertTimeIntToString(int(shifts[shiftPos].End)),
+			Date:            date,
+			StartOfOwnShift: startOfOwnShift,
+			EndOfOwnShift:   endOfOwnShift,
+			OwnShift:        ownShift,
 			Incidents:       incidents,
 		}
 
-		bunt.Println()
-		return temp.Execute(os.Stdout, input)
+		if isStructuredOutput() {
+			var report bytes.Buffer
+			if err := temp.Execute(&report, input); err != nil {
+				return err
+			}
+
+			incidentOutputs, err := newIncidentOutputs(cmd.Context(), client, incidents)
+			if err != nil {
+				return err
+			}
+
+			return printStructured(out, shiftReportOutput{
+				Username:      username,
+				Date:          date,
+				OwnShiftStart: ownShift.Start,
+				OwnShiftEnd:   ownShift.End,
+				Incidents:     incidentOutputs,
+				Report:        report.String(),
+			})
+		}
+
+		bunt.Fprintln(out)
+		return temp.Execute(out, input)
 	},
 }
 
-func convertTimeIntToString(i int) string {
-	return bunt.Sprintf("%02d:%02d", i/60, i%60)
+// reportTemplateFuncs are the functions available in the templates of the .pd.yml file
+var reportTemplateFuncs = map[string]interface{}{
+	"makeSlice":                    makeSlice,
+	"getCategoryMatchingIncidents": getCategoryMatchingIncidents,
+}
+
+// newReportTemplate parses the template of a shift report or handover
+func newReportTemplate(data string) (*template.Template, error) {
+	temp, err := template.New("template").Funcs(template.FuncMap(reportTemplateFuncs)).Parse(data)
+	if err != nil {
+		return nil, wrap.Error(err, "failed to parse the template")
+	}
+
+	return temp, nil
 }
 
 func makeSlice(args ...interface{}) []interface{} {
@@ -135,6 +213,7 @@ func init() {
 	rootCmd.AddCommand(shiftReportCmd)
 
 	shiftReportCmd.Flags().StringVar(&shiftReportCmdSettings.id, "id", "", "use custom ID")
+	shiftReportCmd.Flags().StringSliceVar(&shiftReportCmdSettings.involvement, "involvement", nil, "only incidents the user was assigned to, acknowledged, resolved, or notified about (default all of them)")
 	shiftReportCmd.Flags().StringVar(&shiftReportCmdSettings.templateName, "template", "", "set path of shift-report.template file")
-	shiftReportCmd.Flags().StringVar(&shiftReportCmdSettings.date, "date", "", "set date of shift report")
+	shiftReportCmd.Flags().StringVar(&shiftReportCmdSettings.date, "date", "", "set date of shift report (date, yesterday, last-shift, ...)")
 }
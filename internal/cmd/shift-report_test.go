@@ -0,0 +1,84 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShiftReportOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "missing flags",
+			args:     []string{"shift-report", "--id", "PUSER01"},
+			expected: []string{"Please use all flags!"},
+		},
+		{
+			name: "report of the day",
+			args: []string{"shift-report", "--id", "PUSER01", "--template", "report", "--date", "2022-11-07"},
+			expected: []string{
+				"Report of Jane Doe for 2022-11-07:\n- #1 Disk full on db-1\n- #2 Certificate expires\n",
+			},
+		},
+		{
+			name:     "report of another user",
+			args:     []string{"shift-report", "--id", "PUSER02", "--template", "report", "--date", "2022-11-07"},
+			expected: []string{"Report of John Roe for 2022-11-07:\n- #3 Queue backlog\n"},
+		},
+		{
+			name:     "report of a day without incidents",
+			args:     []string{"shift-report", "--id", "PUSER01", "--template", "report", "--date", "2022-11-08"},
+			expected: []string{"Report of Jane Doe for 2022-11-08:\n"},
+		},
+		{
+			name: "report as JSON",
+			args: []string{"shift-report", "--id", "PUSER01", "--template", "report", "--date", "2022-11-07", "--output", "json"},
+			expected: []string{
+				`"username": "Jane Doe"`,
+				`"date": "2022-11-07"`,
+				`"own_shift_start": "2022-11-07T08:00:00Z"`,
+				`"own_shift_end": "2022-11-07T20:00:00Z"`,
+				`"id": "PINC002"`,
+				`"report": "Report of Jane Doe for 2022-11-07:\n- #1 Disk full on db-1\n- #2 Certificate expires\n"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runCommand(t, newTestClient(), testConfig, "", tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(out, expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, out)
+				}
+			}
+		})
+	}
+}
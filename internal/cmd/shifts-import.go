@@ -0,0 +1,92 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/gonvenience/bunt"
+	"github.com/homeport/pd/internal/pd"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var shiftsImportCmdSettings struct {
+	scheduleID string
+	dryRun     bool
+}
+
+// shiftsImportCmd represents the shifts import command
+var shiftsImportCmd = &cobra.Command{
+	Use:   "import",
+	Args:  cobra.ExactArgs(0),
+	Short: "Import shifts from a PagerDuty schedule",
+	Long:  `Reads the layers of a PagerDuty schedule and writes equivalent shift-times entries into the .pd.yml file`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		shifts, err := pd.ImportShifts(cmd.Context(), client, shiftsImportCmdSettings.scheduleID)
+		if err != nil {
+			return err
+		}
+
+		if isStructuredOutput() && shiftsImportCmdSettings.dryRun {
+			return printStructured(out, shifts)
+		}
+
+		if shiftsImportCmdSettings.dryRun {
+			data, err := yaml.Marshal(map[string]interface{}{"shift-times": shifts})
+			if err != nil {
+				return err
+			}
+
+			bunt.Fprintf(out, "%s", data)
+			return nil
+		}
+
+		if err := pd.ChangeYAMLFile("shift-times", shifts); err != nil {
+			return err
+		}
+
+		if isStructuredOutput() {
+			return printStructured(out, shifts)
+		}
+
+		bunt.Fprintf(out, "\nImported *%d* shifts from schedule SkyBlue{%s}:\n", len(shifts), shiftsImportCmdSettings.scheduleID)
+		for _, shift := range shifts {
+			bunt.Fprintf(out, "  SkyBlue{%s} from %s to %s (%s)\n", shift.Name, shift.Start, shift.End, shift.TZ)
+		}
+
+		bunt.Fprintf(out, "\nPlease run 'LightSlateGray{pd config validate}' to check the imported shifts.\n\n")
+		return nil
+	},
+}
+
+func init() {
+	shiftsCmd.AddCommand(shiftsImportCmd)
+
+	shiftsImportCmd.Flags().StringVar(&shiftsImportCmdSettings.scheduleID, "schedule", "", "ID of the PagerDuty schedule to import")
+	shiftsImportCmd.Flags().BoolVar(&shiftsImportCmdSettings.dryRun, "dry-run", false, "print the shifts instead of writing them into the .pd.yml file")
+	_ = shiftsImportCmd.MarkFlagRequired("schedule")
+}
@@ -0,0 +1,103 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/homeport/pd/internal/pd"
+	"github.com/spf13/cobra"
+)
+
+var testPageCmdSettings struct {
+	user    string
+	service string
+	urgency string
+}
+
+// testPageCmd represents the test-page command
+var testPageCmd = &cobra.Command{
+	Use:   "test-page",
+	Args:  cobra.NoArgs,
+	Short: "Send a test page to a user",
+	Long: `Creates an incident that is assigned to the user to verify the notification
+rules, the service is taken from --service or the test-page-service setting of
+the .pd.yml file, resolve the incident once the page arrived`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateUrgency(testPageCmdSettings.urgency); err != nil {
+			return err
+		}
+
+		serviceName := testPageCmdSettings.service
+		if serviceName == "" {
+			configured, err := pd.GetTestPageService()
+			if err != nil {
+				return err
+			}
+
+			if configured == "" {
+				return errors.New("use --service or configure test-page-service in the .pd.yml file to select the service of the test page")
+			}
+
+			serviceName = configured
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		service, err := pd.ResolveService(ctx, client, serviceName)
+		if err != nil {
+			return err
+		}
+
+		user, err := pd.ResolveUser(ctx, client, testPageCmdSettings.user)
+		if err != nil {
+			return err
+		}
+
+		return createIncident(cmd, client, pagerduty.CreateIncidentOptions{
+			Title:   fmt.Sprintf("Test page for %s", user.Name),
+			Service: &pagerduty.APIReference{ID: service.ID, Type: "service_reference"},
+			Urgency: testPageCmdSettings.urgency,
+			Body: &pagerduty.APIDetails{
+				Type:    "incident_body",
+				Details: fmt.Sprintf("Test page sent with pd at %s to verify the notification rules, please resolve this incident.", pd.InTimezone(time.Now()).Format(time.RFC1123)),
+			},
+			Assignments: []pagerduty.Assignee{
+				{Assignee: pagerduty.APIObject{ID: user.ID, Type: "user_reference"}},
+			},
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(testPageCmd)
+
+	testPageCmd.Flags().StringVar(&testPageCmdSettings.user, "user", "me", "user to page (ID, email, name, or me)")
+	testPageCmd.Flags().StringVar(&testPageCmdSettings.service, "service", "", "service of the test incident (ID or name, defaults to test-page-service of the .pd.yml file)")
+	testPageCmd.Flags().StringVar(&testPageCmdSettings.urgency, "urgency", "high", "urgency of the test page, high or low")
+}
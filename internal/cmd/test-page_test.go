@@ -0,0 +1,135 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTestPage(t *testing.T) {
+	withService := testConfig + "test-page-service: Checkout Worker\n"
+
+	tests := []struct {
+		name     string
+		config   string
+		args     []string
+		expected []string
+		title    string
+		service  string
+		urgency  string
+		assignee string
+		err      string
+	}{
+		{
+			name:   "configured service for the current user",
+			config: withService,
+			args:   []string{"test-page"},
+			expected: []string{
+				"Created incident #5 Test page for Jane Doe (high urgency) for service Checkout Worker",
+				"Link: https://fake.pagerduty.com/incidents/PFAKE05",
+			},
+			title:    "Test page for Jane Doe",
+			service:  "PSERV02",
+			urgency:  "high",
+			assignee: "PUSER01",
+		},
+		{
+			name:   "service and user by name",
+			config: testConfig,
+			args:   []string{"test-page", "--service", "checkout api", "--user", "john roe", "--urgency", "low"},
+			expected: []string{
+				"Created incident #5 Test page for John Roe (low urgency) for service Checkout API",
+				"Link: https://fake.pagerduty.com/incidents/PFAKE05",
+			},
+			title:    "Test page for John Roe",
+			service:  "PSERV01",
+			urgency:  "low",
+			assignee: "PUSER02",
+		},
+		{
+			name:     "user by email",
+			config:   withService,
+			args:     []string{"test-page", "--user", "johanna@example.com"},
+			expected: []string{"Created incident #5 Test page for Johanna Poe (high urgency)"},
+			title:    "Test page for Johanna Poe",
+			service:  "PSERV02",
+			urgency:  "high",
+			assignee: "PUSER03",
+		},
+		{
+			name:   "ambiguous user",
+			config: withService,
+			args:   []string{"test-page", "--user", "Joh"},
+			err:    `user "Joh" is ambiguous, it matches: John Roe, Johanna Poe`,
+		},
+		{
+			name:   "ambiguous service",
+			config: testConfig,
+			args:   []string{"test-page", "--service", "Checkout"},
+			err:    `service "Checkout" is ambiguous, it matches: Checkout API, Checkout Worker`,
+		},
+		{
+			name:   "no service",
+			config: testConfig,
+			args:   []string{"test-page"},
+			err:    "use --service or configure test-page-service in the .pd.yml file to select the service of the test page",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient()
+
+			out, err := runCommand(t, client, tt.config, "", tt.args...)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+
+				if len(client.Incidents) != 4 {
+					t.Errorf("expected no incident to be created")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(out, expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, out)
+				}
+			}
+
+			title, service, urgency, assignees := createdIncident(t, client)
+			if title != tt.title || service != tt.service || urgency != tt.urgency {
+				t.Errorf("expected incident %q of service %s with %s urgency, got %q of service %s with %s urgency", tt.title, tt.service, tt.urgency, title, service, urgency)
+			}
+
+			if len(assignees) != 1 || assignees[0] != tt.assignee {
+				t.Errorf("expected the incident to be assigned to %s, got %v", tt.assignee, assignees)
+			}
+		})
+	}
+}
@@ -33,12 +33,17 @@ var versionCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(0),
 	Short: "Display version",
 	Long:  "Displays the version of this tool",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(version) == 0 {
 			version = "development"
 		}
 
-		bunt.Printf("version DimGray{%s}\n", version)
+		if isStructuredOutput() {
+			return printStructured(cmd.OutOrStdout(), map[string]string{"version": version})
+		}
+
+		bunt.Fprintf(cmd.OutOrStdout(), "version DimGray{%s}\n", version)
+		return nil
 	},
 }
 
@@ -0,0 +1,251 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/gonvenience/bunt"
+	"github.com/gonvenience/wrap"
+	"github.com/homeport/pd/internal/pd"
+	"github.com/spf13/cobra"
+)
+
+// minWatchInterval protects the PagerDuty API from too frequent polling
+const minWatchInterval = 5 * time.Second
+
+var watchCmdSettings struct {
+	interval time.Duration
+	since    string
+	teams    []string
+}
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Args:  cobra.NoArgs,
+	Short: "Watch incidents for changes",
+	Long: `Polls PagerDuty for new log entries and prints triggers, acknowledgements,
+escalations, reassignments, and resolutions of the incidents of your teams as
+they happen, together with a summary of the open incidents, until it is
+interrupted with Ctrl-C`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var (
+			ctx = cmd.Context()
+			out = cmd.OutOrStdout()
+		)
+
+		if watchCmdSettings.interval < minWatchInterval {
+			return fmt.Errorf("the interval must be at least %s", minWatchInterval)
+		}
+
+		since, err := pd.ParseTimeExpression(watchCmdSettings.since, time.Now())
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		teamIDs, err := watchTeamIDs(ctx, client)
+		if err != nil {
+			return err
+		}
+
+		var (
+			watcher     = pd.NewIncidentWatcher(client, teamIDs, since.Start)
+			interactive = !isStructuredOutput() && isTerminal(out)
+			changes     int
+			previous    string
+		)
+
+		for {
+			summary, err := pollIncidentChanges(cmd, client, watcher, teamIDs, &changes, interactive)
+			if ctx.Err() != nil {
+				break
+			}
+
+			// failed polls are reported and repeated with the next interval
+			if err != nil {
+				summary = bunt.Sprintf("FireBrick{%v}", err)
+			}
+
+			// in a log, the summary is only repeated when it changed
+			switch {
+			case isStructuredOutput():
+				if err != nil && summary != previous {
+					bunt.Fprintln(cmd.ErrOrStderr(), summary)
+				}
+
+			case interactive:
+				bunt.Fprintf(out, "\r\033[KDimGray{%s} %s", pd.InTimezone(time.Now()).Format("15:04:05"), summary)
+
+			case summary != previous:
+				bunt.Fprintf(out, "DimGray{%s} %s\n", pd.InTimezone(time.Now()).Format("15:04:05"), summary)
+			}
+
+			previous = summary
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(watchCmdSettings.interval):
+			}
+		}
+
+		if interactive {
+			bunt.Fprintln(out)
+		}
+
+		return nil
+	},
+}
+
+// pollIncidentChanges prints the changes since the previous poll and returns
+// the summary line
+func pollIncidentChanges(cmd *cobra.Command, client pd.Client, watcher *pd.IncidentWatcher, teamIDs []string, changes *int, interactive bool) (string, error) {
+	ctx := cmd.Context()
+	updates, err := watcher.Poll(ctx)
+	if err != nil {
+		return "", wrap.Error(err, "failed to poll PagerDuty")
+	}
+
+	*changes += len(updates)
+	if err := printIncidentChanges(cmd.OutOrStdout(), updates, interactive); err != nil {
+		return "", err
+	}
+
+	return watchSummary(ctx, client, teamIDs, *changes)
+}
+
+// watchTeamIDs returns the IDs of the selected teams, or the teams of the
+// current user if no team is selected
+func watchTeamIDs(ctx context.Context, client pd.Client) ([]string, error) {
+	var teamIDs []string
+	for _, value := range watchCmdSettings.teams {
+		team, err := pd.ResolveTeam(ctx, client, value)
+		if err != nil {
+			return nil, err
+		}
+
+		teamIDs = append(teamIDs, team.ID)
+	}
+
+	if len(teamIDs) > 0 {
+		return teamIDs, nil
+	}
+
+	user, err := client.GetCurrentUserWithContext(ctx, pagerduty.GetCurrentUserOptions{})
+	if err != nil {
+		return nil, wrap.Error(err, "it seems like the authtoken is not set correctly or outdated. Please update the authtoken in the .pd.yml file. If you don't know how to create your authtoken, this might help:\n https://support.pagerduty.com/docs/generating-api-keys#generating-a-personal-rest-api-key\n")
+	}
+
+	if teamIDs = listTeamIDs(*user); len(teamIDs) == 0 {
+		return nil, errors.New("this PagerDuty-account is not part of any teams, use --team to select the teams to watch")
+	}
+
+	return teamIDs, nil
+}
+
+// printIncidentChanges prints one line per change, in an interactive terminal
+// the summary line is cleared first
+func printIncidentChanges(out io.Writer, changes []pd.IncidentChange, interactive bool) error {
+	for _, change := range changes {
+		if isStructuredOutput() {
+			err := printStructuredStream(out, incidentChangeOutput{
+				timelineEventOutput: timelineEventOutput{
+					Time:    change.Time,
+					Type:    change.Type,
+					Actor:   change.Actor,
+					Summary: change.Summary,
+				},
+				Incident: incidentReferenceOutput{
+					ID:    change.Incident.ID,
+					Title: change.Incident.Summary,
+					URL:   change.Incident.HTMLURL,
+				},
+			})
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if interactive {
+			bunt.Fprint(out, "\r\033[K")
+		}
+
+		bunt.Fprintf(out, "%s  %s  *%s*: %s\n",
+			pd.InTimezone(change.Time).Format("15:04:05"),
+			timelineStep(fmt.Sprintf("%-11s", change.Type)),
+			change.Incident.Summary,
+			strings.Join(strings.Fields(change.Summary), " "),
+		)
+	}
+
+	return nil
+}
+
+// watchSummary returns the summary line with the number of open incidents,
+// which includes incidents of all dates and not only the recent ones
+func watchSummary(ctx context.Context, client pd.Client, teamIDs []string, changes int) (string, error) {
+	incidents, err := pd.ListAllIncidents(ctx, client, pagerduty.ListIncidentsOptions{
+		DateRange: "all",
+		TeamIDs:   teamIDs,
+		Statuses:  []string{"triggered", "acknowledged"},
+	})
+	if err != nil {
+		return "", wrap.Error(err, "failed to get open incidents")
+	}
+
+	var triggered, acknowledged int
+	for _, incident := range incidents {
+		switch incident.Status {
+		case "triggered":
+			triggered++
+
+		case "acknowledged":
+			acknowledged++
+		}
+	}
+
+	return bunt.Sprintf("FireBrick{%d triggered}, Gold{%d acknowledged}, %d changes seen DimGray{(Ctrl-C to stop)}",
+		triggered,
+		acknowledged,
+		changes,
+	), nil
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().DurationVar(&watchCmdSettings.interval, "interval", 30*time.Second, "time between two polls")
+	watchCmd.Flags().StringVar(&watchCmdSettings.since, "since", "now", "also report the changes since the given time (like 1h or today)")
+	watchCmd.Flags().StringSliceVar(&watchCmdSettings.teams, "team", nil, "watch incidents of team (ID or name), instead of the teams of the user")
+}
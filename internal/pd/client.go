@@ -0,0 +1,53 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"context"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+// Client describes the subset of the PagerDuty API that is used by the pd
+// commands, it is satisfied by *pagerduty.Client and by the FakeClient
+type Client interface {
+	GetCurrentUserWithContext(ctx context.Context, o pagerduty.GetCurrentUserOptions) (*pagerduty.User, error)
+	GetUserWithContext(ctx context.Context, id string, o pagerduty.GetUserOptions) (*pagerduty.User, error)
+	ListUsersWithContext(ctx context.Context, o pagerduty.ListUsersOptions) (*pagerduty.ListUsersResponse, error)
+	ListOnCallsWithContext(ctx context.Context, o pagerduty.ListOnCallOptions) (*pagerduty.ListOnCallsResponse, error)
+	ListIncidentsWithContext(ctx context.Context, o pagerduty.ListIncidentsOptions) (*pagerduty.ListIncidentsResponse, error)
+	ListIncidentLogEntriesWithContext(ctx context.Context, id string, o pagerduty.ListIncidentLogEntriesOptions) (*pagerduty.ListIncidentLogEntriesResponse, error)
+	ListLogEntriesWithContext(ctx context.Context, o pagerduty.ListLogEntriesOptions) (*pagerduty.ListLogEntryResponse, error)
+	ListIncidentNotesWithContext(ctx context.Context, id string) ([]pagerduty.IncidentNote, error)
+	GetScheduleWithContext(ctx context.Context, id string, o pagerduty.GetScheduleOptions) (*pagerduty.Schedule, error)
+	ListSchedulesWithContext(ctx context.Context, o pagerduty.ListSchedulesOptions) (*pagerduty.ListSchedulesResponse, error)
+	ListTeamsWithContext(ctx context.Context, o pagerduty.ListTeamOptions) (*pagerduty.ListTeamResponse, error)
+	ListEscalationPoliciesWithContext(ctx context.Context, o pagerduty.ListEscalationPoliciesOptions) (*pagerduty.ListEscalationPoliciesResponse, error)
+	ListUserContactMethodsWithContext(ctx context.Context, userID string) (*pagerduty.ListContactMethodsResponse, error)
+	ListServicesWithContext(ctx context.Context, o pagerduty.ListServiceOptions) (*pagerduty.ListServiceResponse, error)
+	GetIncidentWithContext(ctx context.Context, id string) (*pagerduty.Incident, error)
+	ManageIncidentsWithContext(ctx context.Context, from string, incidents []pagerduty.ManageIncidentsOptions) (*pagerduty.ListIncidentsResponse, error)
+	SnoozeIncidentWithContext(ctx context.Context, id string, duration uint) (*pagerduty.Incident, error)
+	CreateIncidentWithContext(ctx context.Context, from string, o *pagerduty.CreateIncidentOptions) (*pagerduty.Incident, error)
+	CreateIncidentNoteWithContext(ctx context.Context, id string, note pagerduty.IncidentNote) (*pagerduty.IncidentNote, error)
+}
+
+var _ Client = &pagerduty.Client{}
@@ -0,0 +1,122 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+const icsTimeLayout = "20060102T150405Z"
+
+// WriteICalendar writes the on-call windows of the user as an RFC 5545
+// calendar with one event per window, the event UIDs are derived from the
+// user, escalation policy, level, and start so that re-imports update
+// existing events, permanent on-calls are limited to the exported time range
+func WriteICalendar(w io.Writer, user *pagerduty.User, windows []OnCallWindow, timeRange TimeRange) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//homeport//pd//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escapeICSText("PagerDuty on-call "+user.Name),
+	}
+
+	stamp := time.Now().UTC().Format(icsTimeLayout)
+	for _, window := range windows {
+		bounded := window.Bounded(timeRange.Start, timeRange.End)
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+icsUID(user.ID, window),
+			"DTSTAMP:"+stamp,
+			"DTSTART:"+bounded.Start.UTC().Format(icsTimeLayout),
+			"DTEND:"+bounded.End.UTC().Format(icsTimeLayout),
+			"SUMMARY:"+escapeICSText(fmt.Sprintf("On-call: %s (level %d)", window.EscalationPolicy.Summary, window.EscalationLevel)),
+			"DESCRIPTION:"+escapeICSText(window.EscalationPolicy.HTMLURL),
+			"TRANSP:OPAQUE",
+			"END:VEVENT",
+		)
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, foldICSLine(line)+"\r\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// icsUID derives the UID of the event from the identity of the window only,
+// the end is left out so that a prolonged window keeps its event
+func icsUID(userID string, window OnCallWindow) string {
+	hash := sha1.Sum([]byte(fmt.Sprintf("%s/%s/%d/%s",
+		userID,
+		window.EscalationPolicy.ID,
+		window.EscalationLevel,
+		window.Start.UTC().Format(icsTimeLayout),
+	)))
+
+	return fmt.Sprintf("%x@pd.homeport.github.io", hash)
+}
+
+// escapeICSText escapes a TEXT value according to RFC 5545 section 3.3.11
+func escapeICSText(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(text)
+}
+
+// foldICSLine splits lines longer than 75 octets according to RFC 5545
+// section 3.1 without breaking multi-byte characters
+func foldICSLine(line string) string {
+	const limit = 75
+
+	var (
+		result strings.Builder
+		length int
+	)
+
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > limit {
+			result.WriteString("\r\n ")
+			length = 1
+		}
+
+		result.WriteRune(r)
+		length += size
+	}
+
+	return result.String()
+}
@@ -0,0 +1,72 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+func TestICalendarUIDsAreStable(t *testing.T) {
+	var (
+		user   = &pagerduty.User{APIObject: pagerduty.APIObject{ID: "PUSER01"}, Name: "Jane Doe"}
+		policy = pagerduty.EscalationPolicy{APIObject: pagerduty.APIObject{ID: "PPOLICY", HTMLURL: "https://example.pagerduty.com/escalation_policies/PPOLICY"}, Name: "Platform"}
+		start  = time.Date(2022, 11, 7, 8, 0, 0, 0, time.UTC)
+	)
+
+	window := func(end time.Time, level uint) OnCallWindow {
+		return OnCallWindow{TimeRange: TimeRange{Start: start, End: end}, EscalationPolicy: policy, EscalationLevel: level}
+	}
+
+	tests := []struct {
+		name  string
+		a     OnCallWindow
+		b     OnCallWindow
+		equal bool
+	}{
+		{name: "same window", a: window(start.Add(8*time.Hour), 1), b: window(start.Add(8*time.Hour), 1), equal: true},
+		{name: "prolonged window", a: window(start.Add(8*time.Hour), 1), b: window(start.Add(12*time.Hour), 1), equal: true},
+		{name: "other level", a: window(start.Add(8*time.Hour), 1), b: window(start.Add(8*time.Hour), 2), equal: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if equal := icsUID(user.ID, tt.a) == icsUID(user.ID, tt.b); equal != tt.equal {
+				t.Errorf("expected UIDs to be equal: %v, got %v", tt.equal, equal)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := WriteICalendar(&buf, user, []OnCallWindow{window(start.Add(8*time.Hour), 1)}, TimeRange{Start: start, End: start.AddDate(0, 0, 7)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{"BEGIN:VCALENDAR\r\n", "UID:" + icsUID(user.ID, window(start, 1)) + "\r\n", "DTSTART:20221107T080000Z\r\n", "DTEND:20221107T160000Z\r\n", "END:VCALENDAR\r\n"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected calendar to contain %q, got:\n%s", expected, buf.String())
+		}
+	}
+}
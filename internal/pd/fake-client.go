@@ -0,0 +1,553 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+// FakeClient is an in-memory implementation of the Client interface, which
+// serves the configured PagerDuty objects without any network access
+type FakeClient struct {
+	CurrentUser *pagerduty.User
+	Users       []pagerduty.User
+	OnCalls     []pagerduty.OnCall
+	Incidents   []pagerduty.Incident
+	LogEntries  map[string][]pagerduty.LogEntry
+	Notes       map[string][]pagerduty.IncidentNote
+	Schedules   []pagerduty.Schedule
+	Teams       []pagerduty.Team
+	Policies    []pagerduty.EscalationPolicy
+	Contacts    map[string][]pagerduty.ContactMethod
+	Services    []pagerduty.Service
+
+	// mutex guards the incidents and notes, which are changed by the incident actions
+	mutex sync.Mutex
+}
+
+var _ Client = &FakeClient{}
+
+// GetCurrentUserWithContext returns the configured current user
+func (f *FakeClient) GetCurrentUserWithContext(_ context.Context, _ pagerduty.GetCurrentUserOptions) (*pagerduty.User, error) {
+	if f.CurrentUser == nil {
+		return nil, notFound("current user")
+	}
+
+	return f.CurrentUser, nil
+}
+
+// GetUserWithContext returns the user with the given ID
+func (f *FakeClient) GetUserWithContext(_ context.Context, id string, _ pagerduty.GetUserOptions) (*pagerduty.User, error) {
+	if f.CurrentUser != nil && f.CurrentUser.ID == id {
+		return f.CurrentUser, nil
+	}
+
+	for i := range f.Users {
+		if f.Users[i].ID == id {
+			return &f.Users[i], nil
+		}
+	}
+
+	return nil, notFound("user " + id)
+}
+
+// ListUsersWithContext returns all users matching the query and team filter
+func (f *FakeClient) ListUsersWithContext(_ context.Context, o pagerduty.ListUsersOptions) (*pagerduty.ListUsersResponse, error) {
+	var result []pagerduty.User
+	for _, user := range f.Users {
+		if !matchesQuery(o.Query, user.Name) && !matchesQuery(o.Query, user.Email) {
+			continue
+		}
+
+		if !containsAny(o.TeamIDs, teamIDsOf(user.Teams)) {
+			continue
+		}
+
+		result = append(result, user)
+	}
+
+	page, list := paginate(result, o.Offset, o.Limit)
+	return &pagerduty.ListUsersResponse{APIListObject: list, Users: page}, nil
+}
+
+// ListOnCallsWithContext returns all on-calls matching the user, escalation
+// policy, and schedule filters
+func (f *FakeClient) ListOnCallsWithContext(_ context.Context, o pagerduty.ListOnCallOptions) (*pagerduty.ListOnCallsResponse, error) {
+	var result []pagerduty.OnCall
+	for _, oncall := range f.OnCalls {
+		if !containsAny(o.UserIDs, []string{oncall.User.ID}) ||
+			!containsAny(o.EscalationPolicyIDs, []string{oncall.EscalationPolicy.ID}) ||
+			!containsAny(o.ScheduleIDs, []string{oncall.Schedule.ID}) {
+			continue
+		}
+
+		result = append(result, oncall)
+	}
+
+	page, list := paginate(result, o.Offset, o.Limit)
+	return &pagerduty.ListOnCallsResponse{APIListObject: list, OnCalls: page}, nil
+}
+
+// ListIncidentsWithContext returns all incidents matching the time range,
+// team, status, service, and urgency filters
+func (f *FakeClient) ListIncidentsWithContext(_ context.Context, o pagerduty.ListIncidentsOptions) (*pagerduty.ListIncidentsResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var result []pagerduty.Incident
+	for _, incident := range f.Incidents {
+		if !withinTimeRange(incident.CreatedAt, o.Since, o.Until) {
+			continue
+		}
+
+		var teamIDs []string
+		for _, team := range incident.Teams {
+			teamIDs = append(teamIDs, team.ID)
+		}
+
+		if !containsAny(o.TeamIDs, teamIDs) ||
+			!containsAny(o.Statuses, []string{incident.Status}) ||
+			!containsAny(o.ServiceIDs, []string{incident.Service.ID}) ||
+			!containsAny(o.Urgencies, []string{incident.Urgency}) {
+			continue
+		}
+
+		result = append(result, incident)
+	}
+
+	page, list := paginate(result, o.Offset, o.Limit)
+	return &pagerduty.ListIncidentsResponse{APIListObject: list, Incidents: page}, nil
+}
+
+// ListIncidentLogEntriesWithContext returns the log entries of the incident
+func (f *FakeClient) ListIncidentLogEntriesWithContext(_ context.Context, id string, o pagerduty.ListIncidentLogEntriesOptions) (*pagerduty.ListIncidentLogEntriesResponse, error) {
+	page, list := paginate(f.LogEntries[id], o.Offset, o.Limit)
+	return &pagerduty.ListIncidentLogEntriesResponse{APIListObject: list, LogEntries: page}, nil
+}
+
+// ListLogEntriesWithContext returns the log entries of all incidents in the
+// time range, the overview leaves out the notifications
+func (f *FakeClient) ListLogEntriesWithContext(_ context.Context, o pagerduty.ListLogEntriesOptions) (*pagerduty.ListLogEntryResponse, error) {
+	var ids []string
+	for id := range f.LogEntries {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	var result []pagerduty.LogEntry
+	for _, id := range ids {
+		for i, logEntry := range f.LogEntries[id] {
+			if !withinTimeRange(logEntry.CreatedAt, o.Since, o.Until) {
+				continue
+			}
+
+			if o.IsOverview && logEntry.Type == "notify_log_entry" {
+				continue
+			}
+
+			if logEntry.ID == "" {
+				logEntry.ID = fmt.Sprintf("%s-%d", id, i)
+			}
+
+			if logEntry.Incident.ID == "" {
+				logEntry.Incident.ID = id
+			}
+
+			result = append(result, logEntry)
+		}
+	}
+
+	page, list := paginate(result, o.Offset, o.Limit)
+	return &pagerduty.ListLogEntryResponse{APIListObject: list, LogEntries: page}, nil
+}
+
+// GetIncidentWithContext returns the incident with the given ID
+func (f *FakeClient) GetIncidentWithContext(_ context.Context, id string) (*pagerduty.Incident, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for i := range f.Incidents {
+		if f.Incidents[i].ID == id {
+			incident := f.Incidents[i]
+			return &incident, nil
+		}
+	}
+
+	return nil, notFound("incident " + id)
+}
+
+// ManageIncidentsWithContext changes the status, assignments, escalation
+// policy, and priority of the incidents
+func (f *FakeClient) ManageIncidentsWithContext(_ context.Context, _ string, incidents []pagerduty.ManageIncidentsOptions) (*pagerduty.ListIncidentsResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var result []pagerduty.Incident
+	for _, change := range incidents {
+		incident := f.incident(change.ID)
+		if incident == nil {
+			return nil, notFound("incident " + change.ID)
+		}
+
+		if change.Status != "" {
+			incident.Status = change.Status
+		}
+
+		if len(change.Assignments) > 0 {
+			incident.Assignments = nil
+			for _, assignee := range change.Assignments {
+				incident.Assignments = append(incident.Assignments, pagerduty.Assignment{Assignee: assignee.Assignee})
+			}
+		}
+
+		if change.EscalationPolicy != nil {
+			incident.EscalationPolicy = pagerduty.APIObject{ID: change.EscalationPolicy.ID, Type: change.EscalationPolicy.Type}
+		}
+
+		if change.Priority != nil {
+			incident.Priority = &pagerduty.Priority{APIObject: pagerduty.APIObject{ID: change.Priority.ID}}
+		}
+
+		result = append(result, *incident)
+	}
+
+	return &pagerduty.ListIncidentsResponse{Incidents: result}, nil
+}
+
+// CreateIncidentWithContext adds a triggered incident to the incidents of the
+// service, it is assigned to the given assignees or else to the current user
+func (f *FakeClient) CreateIncidentWithContext(_ context.Context, _ string, o *pagerduty.CreateIncidentOptions) (*pagerduty.Incident, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if o.Title == "" || o.Service == nil {
+		return nil, badRequest("title and service are required")
+	}
+
+	var service *pagerduty.Service
+	for i := range f.Services {
+		if f.Services[i].ID == o.Service.ID {
+			service = &f.Services[i]
+		}
+	}
+
+	if service == nil {
+		return nil, notFound("service " + o.Service.ID)
+	}
+
+	var number uint
+	for _, incident := range f.Incidents {
+		if incident.IncidentNumber > number {
+			number = incident.IncidentNumber
+		}
+	}
+
+	id := fmt.Sprintf("PFAKE%02d", number+1)
+	now := time.Now().UTC().Format(time.RFC3339)
+	incident := pagerduty.Incident{
+		APIObject:          pagerduty.APIObject{ID: id, Type: "incident", HTMLURL: "https://fake.pagerduty.com/incidents/" + id},
+		IncidentNumber:     number + 1,
+		Title:              o.Title,
+		Status:             "triggered",
+		Urgency:            o.Urgency,
+		CreatedAt:          now,
+		LastStatusChangeAt: now,
+		Service:            pagerduty.APIObject{ID: service.ID, Summary: service.Name},
+		EscalationPolicy:   service.EscalationPolicy.APIObject,
+	}
+
+	for _, team := range service.Teams {
+		incident.Teams = append(incident.Teams, team.APIObject)
+	}
+
+	if incident.Urgency == "" {
+		incident.Urgency = "high"
+	}
+
+	if o.Body != nil {
+		incident.Description = o.Body.Details
+	}
+
+	for _, assignee := range o.Assignments {
+		incident.Assignments = append(incident.Assignments, pagerduty.Assignment{Assignee: assignee.Assignee})
+	}
+
+	if len(incident.Assignments) == 0 && f.CurrentUser != nil {
+		incident.Assignments = []pagerduty.Assignment{{Assignee: f.CurrentUser.APIObject}}
+	}
+
+	f.Incidents = append(f.Incidents, incident)
+	return &incident, nil
+}
+
+// SnoozeIncidentWithContext snoozes the incident, which has to be acknowledged
+func (f *FakeClient) SnoozeIncidentWithContext(_ context.Context, id string, _ uint) (*pagerduty.Incident, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	incident := f.incident(id)
+	if incident == nil {
+		return nil, notFound("incident " + id)
+	}
+
+	if incident.Status != "acknowledged" {
+		return nil, badRequest("incident " + id + " is not acknowledged")
+	}
+
+	return incident, nil
+}
+
+func (f *FakeClient) incident(id string) *pagerduty.Incident {
+	for i := range f.Incidents {
+		if f.Incidents[i].ID == id {
+			return &f.Incidents[i]
+		}
+	}
+
+	return nil
+}
+
+// ListIncidentNotesWithContext returns the notes of the incident
+func (f *FakeClient) ListIncidentNotesWithContext(_ context.Context, id string) ([]pagerduty.IncidentNote, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.Notes[id], nil
+}
+
+// CreateIncidentNoteWithContext adds the note to the notes of the incident
+func (f *FakeClient) CreateIncidentNoteWithContext(_ context.Context, id string, note pagerduty.IncidentNote) (*pagerduty.IncidentNote, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.incident(id) == nil {
+		return nil, notFound("incident " + id)
+	}
+
+	if f.Notes == nil {
+		f.Notes = map[string][]pagerduty.IncidentNote{}
+	}
+
+	note.ID = fmt.Sprintf("PNOTE%02d", len(f.Notes[id])+1)
+	f.Notes[id] = append(f.Notes[id], note)
+
+	return &note, nil
+}
+
+// GetScheduleWithContext returns the schedule with the given ID
+func (f *FakeClient) GetScheduleWithContext(_ context.Context, id string, _ pagerduty.GetScheduleOptions) (*pagerduty.Schedule, error) {
+	for i := range f.Schedules {
+		if f.Schedules[i].ID == id {
+			return &f.Schedules[i], nil
+		}
+	}
+
+	return nil, notFound("schedule " + id)
+}
+
+// ListSchedulesWithContext returns all schedules matching the query
+func (f *FakeClient) ListSchedulesWithContext(_ context.Context, o pagerduty.ListSchedulesOptions) (*pagerduty.ListSchedulesResponse, error) {
+	var result []pagerduty.Schedule
+	for _, schedule := range f.Schedules {
+		if matchesQuery(o.Query, schedule.Name) {
+			result = append(result, schedule)
+		}
+	}
+
+	page, list := paginate(result, o.Offset, o.Limit)
+	return &pagerduty.ListSchedulesResponse{APIListObject: list, Schedules: page}, nil
+}
+
+// ListTeamsWithContext returns all teams matching the query
+func (f *FakeClient) ListTeamsWithContext(_ context.Context, o pagerduty.ListTeamOptions) (*pagerduty.ListTeamResponse, error) {
+	var result []pagerduty.Team
+	for _, team := range f.Teams {
+		if matchesQuery(o.Query, team.Name) {
+			result = append(result, team)
+		}
+	}
+
+	page, list := paginate(result, o.Offset, o.Limit)
+	return &pagerduty.ListTeamResponse{APIListObject: list, Teams: page}, nil
+}
+
+// ListServicesWithContext returns all services matching the query and team filters
+func (f *FakeClient) ListServicesWithContext(_ context.Context, o pagerduty.ListServiceOptions) (*pagerduty.ListServiceResponse, error) {
+	var result []pagerduty.Service
+	for _, service := range f.Services {
+		var teamIDs []string
+		for _, team := range service.Teams {
+			teamIDs = append(teamIDs, team.ID)
+		}
+
+		if !matchesQuery(o.Query, service.Name) || !containsAny(o.TeamIDs, teamIDs) {
+			continue
+		}
+
+		result = append(result, service)
+	}
+
+	page, list := paginate(result, o.Offset, o.Limit)
+	return &pagerduty.ListServiceResponse{APIListObject: list, Services: page}, nil
+}
+
+// ListEscalationPoliciesWithContext returns all escalation policies matching
+// the query, team, and user filters
+func (f *FakeClient) ListEscalationPoliciesWithContext(_ context.Context, o pagerduty.ListEscalationPoliciesOptions) (*pagerduty.ListEscalationPoliciesResponse, error) {
+	var result []pagerduty.EscalationPolicy
+	for _, policy := range f.Policies {
+		var teamIDs []string
+		for _, team := range policy.Teams {
+			teamIDs = append(teamIDs, team.ID)
+		}
+
+		var userIDs []string
+		for _, rule := range policy.EscalationRules {
+			for _, target := range rule.Targets {
+				userIDs = append(userIDs, target.ID)
+			}
+		}
+
+		if !matchesQuery(o.Query, policy.Name) ||
+			!containsAny(o.TeamIDs, teamIDs) ||
+			!containsAny(o.UserIDs, userIDs) {
+			continue
+		}
+
+		result = append(result, policy)
+	}
+
+	page, list := paginate(result, o.Offset, o.Limit)
+	return &pagerduty.ListEscalationPoliciesResponse{APIListObject: list, EscalationPolicies: page}, nil
+}
+
+// ListUserContactMethodsWithContext returns the contact methods of the user
+func (f *FakeClient) ListUserContactMethodsWithContext(_ context.Context, userID string) (*pagerduty.ListContactMethodsResponse, error) {
+	return &pagerduty.ListContactMethodsResponse{ContactMethods: f.Contacts[userID]}, nil
+}
+
+func notFound(what string) error {
+	return pagerduty.APIError{
+		StatusCode: http.StatusNotFound,
+		APIError: pagerduty.NullAPIErrorObject{
+			Valid: true,
+			ErrorObject: pagerduty.APIErrorObject{
+				Code:    2100,
+				Message: what + " not found",
+			},
+		},
+	}
+}
+
+func badRequest(message string) error {
+	return pagerduty.APIError{
+		StatusCode: http.StatusBadRequest,
+		APIError: pagerduty.NullAPIErrorObject{
+			Valid: true,
+			ErrorObject: pagerduty.APIErrorObject{
+				Code:    2001,
+				Message: message,
+			},
+		},
+	}
+}
+
+// paginate returns the requested page of the list, a limit of zero results
+// in the PagerDuty default page size of 25
+func paginate[T any](list []T, offset uint, limit uint) ([]T, pagerduty.APIListObject) {
+	if limit == 0 {
+		limit = 25
+	}
+
+	total := uint(len(list))
+	if offset > total {
+		offset = total
+	}
+
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	return list[offset:end], pagerduty.APIListObject{
+		Limit:  limit,
+		Offset: offset,
+		More:   end < total,
+		Total:  total,
+	}
+}
+
+// matchesQuery returns true if the value contains the query, ignoring case
+func matchesQuery(query string, value string) bool {
+	return strings.Contains(strings.ToLower(value), strings.ToLower(query))
+}
+
+// containsAny returns true if no filter is set, or if at least one of the
+// values is part of the filter
+func containsAny(filter []string, values []string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+
+	for _, f := range filter {
+		for _, v := range values {
+			if f == v {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func teamIDsOf(teams []pagerduty.Team) []string {
+	result := make([]string, len(teams))
+	for i, team := range teams {
+		result[i] = team.ID
+	}
+	return result
+}
+
+func withinTimeRange(timestamp string, since string, until string) bool {
+	t, err := ParseTimestamp(timestamp)
+	if err != nil {
+		return true
+	}
+
+	if start, err := ParseTimestamp(since); err == nil && t.Before(start) {
+		return false
+	}
+
+	if end, err := ParseTimestamp(until); err == nil && !t.Before(end) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,141 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+func TestFakeClientListIncidents(t *testing.T) {
+	client := &FakeClient{
+		Incidents: []pagerduty.Incident{
+			{APIObject: pagerduty.APIObject{ID: "P1"}, Status: "triggered", Urgency: "high", CreatedAt: "2022-11-01T10:00:00Z", Teams: []pagerduty.APIObject{{ID: "TEAM1"}}},
+			{APIObject: pagerduty.APIObject{ID: "P2"}, Status: "acknowledged", Urgency: "low", CreatedAt: "2022-11-02T10:00:00Z", Teams: []pagerduty.APIObject{{ID: "TEAM2"}}},
+			{APIObject: pagerduty.APIObject{ID: "P3"}, Status: "resolved", Urgency: "high", CreatedAt: "2022-11-03T10:00:00Z", Teams: []pagerduty.APIObject{{ID: "TEAM1"}}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		options  pagerduty.ListIncidentsOptions
+		expected []string
+		more     bool
+	}{
+		{name: "no filter", expected: []string{"P1", "P2", "P3"}},
+		{name: "statuses", options: pagerduty.ListIncidentsOptions{Statuses: []string{"triggered", "acknowledged"}}, expected: []string{"P1", "P2"}},
+		{name: "urgencies", options: pagerduty.ListIncidentsOptions{Urgencies: []string{"high"}}, expected: []string{"P1", "P3"}},
+		{name: "teams", options: pagerduty.ListIncidentsOptions{TeamIDs: []string{"TEAM2"}}, expected: []string{"P2"}},
+		{name: "time range", options: pagerduty.ListIncidentsOptions{Since: "2022-11-02T00:00:00Z", Until: "2022-11-03T10:00:00Z"}, expected: []string{"P2"}},
+		{name: "first page", options: pagerduty.ListIncidentsOptions{Limit: 2}, expected: []string{"P1", "P2"}, more: true},
+		{name: "second page", options: pagerduty.ListIncidentsOptions{Limit: 2, Offset: 2}, expected: []string{"P3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.ListIncidentsWithContext(context.Background(), tt.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var ids []string
+			for _, incident := range resp.Incidents {
+				ids = append(ids, incident.ID)
+			}
+
+			if !equalStrings(ids, tt.expected) {
+				t.Errorf("expected incidents %v, got %v", tt.expected, ids)
+			}
+
+			if resp.More != tt.more {
+				t.Errorf("expected more to be %v, got %v", tt.more, resp.More)
+			}
+		})
+	}
+}
+
+func TestFakeClientConcurrentIncidentChanges(t *testing.T) {
+	client := &FakeClient{
+		Incidents: []pagerduty.Incident{
+			{APIObject: pagerduty.APIObject{ID: "P1"}, Status: "triggered"},
+			{APIObject: pagerduty.APIObject{ID: "P2"}, Status: "triggered"},
+		},
+	}
+
+	var wg sync.WaitGroup
+	for _, id := range []string{"P1", "P2"} {
+		wg.Add(2)
+
+		go func(id string) {
+			defer wg.Done()
+			changes := []pagerduty.ManageIncidentsOptions{{ID: id, Status: "acknowledged"}}
+			if _, err := client.ManageIncidentsWithContext(context.Background(), "", changes); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(id)
+
+		go func() {
+			defer wg.Done()
+			if _, err := client.ListIncidentsWithContext(context.Background(), pagerduty.ListIncidentsOptions{}); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	resp, err := client.ListIncidentsWithContext(context.Background(), pagerduty.ListIncidentsOptions{Statuses: []string{"acknowledged"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(resp.Incidents) != 2 {
+		t.Errorf("expected both incidents to be acknowledged, got %d", len(resp.Incidents))
+	}
+}
+
+func TestFakeClientNotFound(t *testing.T) {
+	client := &FakeClient{}
+
+	_, err := client.GetIncidentWithContext(context.Background(), "PNONE")
+	var apiErr pagerduty.APIError
+	if !errors.As(err, &apiErr) || !apiErr.NotFound() {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
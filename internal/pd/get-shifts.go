@@ -21,121 +21,314 @@
 package pd
 
 import (
-	"strconv"
+	"fmt"
 	"strings"
 	"time"
+
+	"github.com/gonvenience/wrap"
 )
 
-// Requirement: Shifts must be sorted in .pd.yml file
+const (
+	dateLayout = "2006-01-02"
+
+	// searchHorizonDays limits how far into the future the next start of a
+	// shift is searched, which matters for date specific shifts
+	searchHorizonDays = 400
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
 
 // Shift specifies time range and name of a shift, start and end times are saved in minutes since midnight
+// in the time zone of the shift. A shift with weekdays or dates only starts on these days and takes
+// precedence over the every-day shifts.
 type Shift struct {
-	Start ShiftTime
-	End   ShiftTime
-	Name  string
+	Start    ShiftTime
+	End      ShiftTime
+	Name     string
+	Location *time.Location
+	Weekdays []time.Weekday
+	Dates    []string
+
+	line int
 }
 
 // ShiftTime stores the amount of minutes that passed since midnight or the length of a time period in minutes
 // 3:30 am would be stored as 210 (3 * 60 + 30) and a time period of 6:15 h would be stored as 375 (6 * 60 + 15)
 type ShiftTime int
 
-// GetProbablyOwnShift returns the shift the user probably belongs to because of their time zone
-func GetProbablyOwnShift() (Shift, error) {
+// String returns the shift time in the HH:MM format
+func (t ShiftTime) String() string {
+	return fmt.Sprintf("%02d:%02d", t/60, t%60)
+}
 
-	timeOffsetString := time.Now().String()
-	if offsetLocation := strings.Index(timeOffsetString, " +"); offsetLocation != -1 {
-		timeOffsetString = timeOffsetString[offsetLocation+1 : offsetLocation+6]
-	} else {
-		offsetLocation := strings.Index(timeOffsetString, " -")
-		timeOffsetString = timeOffsetString[offsetLocation+1 : offsetLocation+6]
+// location returns the time zone of the shift, which defaults to UTC
+func (s Shift) location() *time.Location {
+	if s.Location == nil {
+		return time.UTC
 	}
 
-	timeOffset, err := strconv.Atoi(timeOffsetString[1:3])
-	if err != nil {
-		return Shift{}, err
+	return s.Location
+}
+
+// On returns the start and end instant of the shift that starts on the given
+// calendar day in the time zone of the shift, a shift that ends at or before
+// its start time ends on the following day
+func (s Shift) On(year int, month time.Month, day int) TimeRange {
+	loc := s.location()
+
+	endDay := day
+	if s.End <= s.Start {
+		endDay++
 	}
-	timeOffset *= 60
 
-	minutes, err := strconv.Atoi(timeOffsetString[3:])
-	if err != nil {
-		return Shift{}, err
+	return TimeRange{
+		Start: time.Date(year, month, day, int(s.Start)/60, int(s.Start)%60, 0, 0, loc),
+		End:   time.Date(year, month, endDay, int(s.End)/60, int(s.End)%60, 0, 0, loc),
 	}
-	timeOffset += minutes
+}
+
+// StartsOn returns whether the shift starts on the given calendar day
+// according to its weekdays and dates
+func (s Shift) StartsOn(year int, month time.Month, day int) bool {
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
 
-	var middayInUTC ShiftTime
-	if timeOffsetString[0] == '+' {
-		middayInUTC = ShiftTime((36*60 - timeOffset) % (24 * 60))
-	} else {
-		middayInUTC = ShiftTime((12*60 + timeOffset) % (24 * 60))
+	if len(s.Dates) > 0 {
+		for _, d := range s.Dates {
+			if d == date.Format(dateLayout) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(s.Weekdays) > 0 {
+		for _, weekday := range s.Weekdays {
+			if weekday == date.Weekday() {
+				return true
+			}
+		}
+
+		return false
 	}
 
-	return GetShiftByTime(middayInUTC)
+	return true
 }
 
-// GetShiftByTime returns the shift which is active at a specific time
-func GetShiftByTime(time ShiftTime) (Shift, error) {
+// OccurrenceAt returns the time range of the shift that contains the given
+// instant, or false if the shift is not active at that instant
+func (s Shift) OccurrenceAt(t time.Time) (TimeRange, bool) {
+	local := t.In(s.location())
+	for _, offset := range []int{0, -1} {
+		year, month, day := local.Year(), local.Month(), local.Day()+offset
+		if !s.StartsOn(year, month, day) {
+			continue
+		}
+
+		timeRange := s.On(year, month, day)
+		if !t.Before(timeRange.Start) && t.Before(timeRange.End) {
+			return timeRange, true
+		}
+	}
+
+	return TimeRange{}, false
+}
+
+// LastOccurrence returns the time range of the most recent occurrence of the
+// shift that ended before the given instant, or false if the shift was not in
+// charge within the search horizon
+func (s Shift) LastOccurrence(t time.Time) (TimeRange, bool) {
+	local := t.In(s.location())
+	for offset := 0; offset <= searchHorizonDays; offset++ {
+		year, month, day := local.Year(), local.Month(), local.Day()-offset
+		if !s.StartsOn(year, month, day) {
+			continue
+		}
+
+		if timeRange := s.On(year, month, day); !timeRange.End.After(t) {
+			return timeRange, true
+		}
+	}
+
+	return TimeRange{}, false
+}
+
+// NextStart returns the first start of the shift after the given instant,
+// or false if the shift does not start again within the search horizon
+func (s Shift) NextStart(t time.Time) (time.Time, bool) {
+	local := t.In(s.location())
+	for offset := 0; offset <= searchHorizonDays; offset++ {
+		year, month, day := local.Year(), local.Month(), local.Day()+offset
+		if !s.StartsOn(year, month, day) {
+			continue
+		}
+
+		if start := s.On(year, month, day).Start; start.After(t) {
+			return start, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// precedence returns the rank of the shift when several shifts are active
+// at the same time, date specific shifts win over weekday specific shifts,
+// which win over every-day shifts
+func (s Shift) precedence() int {
+	switch {
+	case len(s.Dates) > 0:
+		return 2
+
+	case len(s.Weekdays) > 0:
+		return 1
+
+	default:
+		return 0
+	}
+}
+
+// GetProbablyOwnShift returns the shift the user probably belongs to because of their time zone
+func GetProbablyOwnShift() (Shift, error) {
+	now := time.Now().In(timezone)
+	midday := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, timezone)
 
 	shifts, _, err := LoadShifts()
 	if err != nil {
 		return Shift{}, err
 	}
 
-	rightShift := Shift{}
-	for i, shift := range shifts {
-		if shift.Start < shift.End { // shift starts and ends during the same day
-			if time >= shift.Start && time < shift.End {
-				rightShift = shifts[i]
+	return GetShiftAt(shifts, midday), nil
+}
+
+// GetShiftByTime returns the shift which is active at a specific instant
+func GetShiftByTime(t time.Time) (Shift, error) {
+
+	shifts, _, err := LoadShifts()
+	if err != nil {
+		return Shift{}, err
+	}
+
+	return GetShiftAt(shifts, t), nil
+}
+
+// GetShiftAt returns the shift of the given list which is in charge at the
+// given instant, or an empty shift if none is active
+func GetShiftAt(shifts []Shift, t time.Time) Shift {
+	if pos := activeShiftPos(shifts, t); pos != -1 {
+		return shifts[pos]
+	}
+
+	return Shift{}
+}
+
+// GetNextShift returns the shift that takes over after the shift that is in
+// charge at the given instant, and the instant of the handover, a shift that
+// is never followed by another one hands over to its own next occurrence
+func GetNextShift(shifts []Shift, t time.Time) (Shift, time.Time, error) {
+	currentPos := activeShiftPos(shifts, t)
+	horizon := t.AddDate(0, 0, searchHorizonDays)
+
+	var selfHandover time.Time
+
+	for cursor := t; cursor.Before(horizon); {
+		var next time.Time
+		consider := func(candidate time.Time) {
+			if candidate.After(cursor) && (next.IsZero() || candidate.Before(next)) {
+				next = candidate
+			}
+		}
+
+		if pos := activeShiftPos(shifts, cursor); pos != -1 {
+			if timeRange, ok := shifts[pos].OccurrenceAt(cursor); ok {
+				consider(timeRange.End)
 			}
-		} else {
-			if time >= shift.Start || time < shift.End {
-				rightShift = shifts[i]
+		}
+
+		for _, shift := range shifts {
+			if start, ok := shift.NextStart(cursor); ok {
+				consider(start)
+			}
+		}
+
+		if next.IsZero() {
+			break
+		}
+
+		pos := activeShiftPos(shifts, next)
+		if pos != -1 && pos != currentPos {
+			return shifts[pos], next, nil
+		}
+
+		if pos != -1 && selfHandover.IsZero() {
+			if timeRange, ok := shifts[pos].OccurrenceAt(next); ok && timeRange.Start.Equal(next) {
+				selfHandover = next
 			}
 		}
+
+		cursor = next
+	}
+
+	if !selfHandover.IsZero() {
+		return shifts[currentPos], selfHandover, nil
+	}
+
+	return Shift{}, time.Time{}, fmt.Errorf("there is no shift taking over within the next %d days", searchHorizonDays)
+}
+
+// GetTimeUntilShift returns the duration until the given shift is in charge the next time
+func GetTimeUntilShift(shifts []Shift, shiftPos int) (time.Duration, error) {
+	now := time.Now()
+	start, err := GetNextStartOfShift(shifts, shiftPos, now)
+	if err != nil {
+		return 0, err
 	}
 
-	return rightShift, nil
+	return start.Sub(now).Round(time.Minute), nil
 }
 
-// GetTimeUntilShift returns information about the next shift and time until it starts
-func GetTimeUntilShift(shifts []Shift, shiftPos int) (ShiftTime, error) {
+// GetNextStartOfShift returns the instant after t at which the given shift is in charge the next time
+func GetNextStartOfShift(shifts []Shift, shiftPos int, t time.Time) (time.Time, error) {
+	shift := shifts[shiftPos%len(shifts)]
 
-	timeInUTC := time.Now().UTC()
-	currentTime := ShiftTime(timeInUTC.Hour()*60 + timeInUTC.Minute())
+	horizon := t.AddDate(0, 0, searchHorizonDays)
+	for cursor := t; cursor.Before(horizon); {
+		start, ok := shift.NextStart(cursor)
+		if !ok {
+			break
+		}
 
-	nextShift := shifts[shiftPos%len(shifts)]
+		if activeShiftPos(shifts, start) == shiftPos%len(shifts) {
+			return start, nil
+		}
 
-	timeUntilNextShift := nextShift.Start - currentTime
-	if currentTime > nextShift.Start { // prevents timeUntilNextShift from being negative if the next shift starts during the next day
-		timeUntilNextShift += 24 * 60
+		cursor = start
 	}
 
-	return timeUntilNextShift, nil
+	return time.Time{}, fmt.Errorf("shift %s is not in charge within the next %d days", shift.Name, searchHorizonDays)
 }
 
 // GetCurrentAndOwnShift returns all shifts in a slice, the position of the current shift, and
 // the position of your own-shift, or an error otherwise
 func GetCurrentAndOwnShift() ([]Shift, int, int, error) {
 
-	timeInUTC := time.Now().UTC()
-	currentTime := ShiftTime(timeInUTC.Hour()*60 + timeInUTC.Minute())
+	now := time.Now()
 
 	shifts, ownShiftName, err := LoadShifts()
 	if err != nil {
 		return []Shift{}, 0, 0, err
 	}
 
-	currentShiftPos := -1
+	currentShiftPos := activeShiftPos(shifts, now)
 	ownShiftPos := -1
 	for i, shift := range shifts {
-		if shift.Start < shift.End { // shift starts and ends during the same day
-			if currentTime >= shift.Start && currentTime < shift.End {
-				currentShiftPos = i
-			}
-		} else {
-			if currentTime >= shift.Start || currentTime < shift.End {
-				currentShiftPos = i
-			}
-		}
 		if shift.Name == ownShiftName {
 			ownShiftPos = i
 		}
@@ -144,43 +337,118 @@ func GetCurrentAndOwnShift() ([]Shift, int, int, error) {
 	return shifts, currentShiftPos, ownShiftPos, nil
 }
 
-// LoadShifts loads shifts out of the .pd.yml file
+// GetHandoverShift returns the shift to hand over together with its time
+// range: the own shift while it is in charge, its last completed occurrence
+// otherwise, or the shift before the current one if no own shift is set
+func GetHandoverShift() (Shift, TimeRange, error) {
+	shifts, shiftPos, ownShiftPos, err := GetCurrentAndOwnShift()
+	if err != nil {
+		return Shift{}, TimeRange{}, err
+	}
+
+	if len(shifts) == 0 {
+		return Shift{}, TimeRange{}, fmt.Errorf("there are no shifts configured in the .pd.yml file")
+	}
+
+	now := time.Now()
+	if ownShiftPos != -1 {
+		own := shifts[ownShiftPos]
+		if ownShiftPos == shiftPos {
+			if timeRange, ok := own.OccurrenceAt(now); ok {
+				return own, timeRange, nil
+			}
+		}
+
+		if timeRange, ok := own.LastOccurrence(now); ok {
+			return own, timeRange, nil
+		}
+
+		return Shift{}, TimeRange{}, fmt.Errorf("shift %s was not in charge within the last %d days", own.Name, searchHorizonDays)
+	}
+
+	if shiftPos == -1 {
+		return Shift{}, TimeRange{}, fmt.Errorf("there is no shift in charge at the moment")
+	}
+
+	current, _ := shifts[shiftPos].OccurrenceAt(now)
+	before := current.Start.Add(-time.Nanosecond)
+	if pos := activeShiftPos(shifts, before); pos != -1 {
+		if timeRange, ok := shifts[pos].OccurrenceAt(before); ok {
+			return shifts[pos], timeRange, nil
+		}
+	}
+
+	return Shift{}, TimeRange{}, fmt.Errorf("there is no shift before the current shift %s", shifts[shiftPos].Name)
+}
+
+// LoadShifts loads shifts out of the .pd.yml file, it fails if the shift
+// configuration contains errors, see ValidateShifts for details
 func LoadShifts() ([]Shift, string, error) {
 
-	var err error
-	config, err := loadConfig()
+	profile, err := loadProfile()
 	if err != nil {
 		return nil, "", err
 	}
 
-	finalShifts := make([]Shift, len(config.ShiftTimes))
+	shifts, issues := validateShifts(profile.ShiftTimes)
+
+	var errs []error
+	for _, issue := range issues {
+		if !issue.Warning {
+			errs = append(errs, issue)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, "", wrap.Errors(errs, "the shifts in the .pd.yml file are wrongly configured, run 'pd config validate' for details")
+	}
+
+	return shifts, profile.OwnShift, nil
+}
 
-	stringToTime := func(str string) (ShiftTime, error) {
-		hours, err := strconv.Atoi(str[:2])
-		if err != nil {
-			return 0, err
+// activeShiftPos returns the position of the shift with the highest
+// precedence that is active at the given instant, or -1 if none is active
+func activeShiftPos(shifts []Shift, t time.Time) int {
+	pos := -1
+	for i, shift := range shifts {
+		if _, active := shift.OccurrenceAt(t); !active {
+			continue
 		}
-		mins, err := strconv.Atoi(str[3:])
-		if err != nil {
-			return 0, err
+
+		if pos == -1 || shift.precedence() >= shifts[pos].precedence() {
+			pos = i
 		}
-		return ShiftTime(hours*60 + mins), nil
 	}
 
-	for i, shift := range config.ShiftTimes {
+	return pos
+}
+
+// parseWeekdays parses weekday names like mon, or ranges like mon-fri
+func parseWeekdays(days []string) ([]time.Weekday, error) {
+	var result []time.Weekday
+	for _, entry := range days {
+		from, to, isRange := strings.Cut(strings.ToLower(entry), "-")
+		if !isRange {
+			to = from
+		}
+
+		first, ok := weekdays[from]
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday %q", from)
+		}
 
-		finalShifts[i] = Shift{}
-		finalShifts[i].Start, err = stringToTime(shift.Start)
-		if err != nil {
-			return nil, "", err
+		last, ok := weekdays[to]
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday %q", to)
 		}
 
-		finalShifts[i].End, err = stringToTime(shift.End)
-		if err != nil {
-			return nil, "", err
+		for day := first; ; day = (day + 1) % 7 {
+			result = append(result, day)
+			if day == last {
+				break
+			}
 		}
-		finalShifts[i].Name = shift.Name
 	}
 
-	return finalShifts, config.OwnShift, nil
+	return result, nil
 }
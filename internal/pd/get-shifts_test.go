@@ -0,0 +1,238 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func mustParseShifts(t *testing.T, configs ...ShiftConfig) []Shift {
+	t.Helper()
+
+	shifts, issues := parseShifts(configs)
+	if len(issues) > 0 {
+		t.Fatalf("unexpected shift issues: %v", issues)
+	}
+
+	return shifts
+}
+
+func mustParseTime(t *testing.T, value string) time.Time {
+	t.Helper()
+
+	result, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatalf("invalid time %q: %v", value, err)
+	}
+
+	return result
+}
+
+func TestShiftHandoverAcrossDaylightSavingTime(t *testing.T) {
+	dayAndNight := mustParseShifts(t,
+		ShiftConfig{Name: "EMEA", Start: "08:00", End: "20:00", TZ: "Europe/Berlin"},
+		ShiftConfig{Name: "AMER", Start: "20:00", End: "08:00", TZ: "Europe/Berlin"},
+	)
+
+	tests := []struct {
+		name     string
+		shifts   []Shift
+		now      string
+		current  string
+		next     string
+		handover string
+	}{
+		{name: "winter time", shifts: dayAndNight, now: "2022-03-26T12:00:00Z", current: "EMEA", next: "AMER", handover: "2022-03-26T19:00:00Z"},
+		{name: "night into the switch", shifts: dayAndNight, now: "2022-03-27T00:30:00Z", current: "AMER", next: "EMEA", handover: "2022-03-27T06:00:00Z"},
+		{name: "summer time", shifts: dayAndNight, now: "2022-03-27T12:00:00Z", current: "EMEA", next: "AMER", handover: "2022-03-27T18:00:00Z"},
+		{name: "back to winter time", shifts: dayAndNight, now: "2022-10-30T12:00:00Z", current: "EMEA", next: "AMER", handover: "2022-10-30T19:00:00Z"},
+		{
+			name:     "single shift from midnight to midnight",
+			shifts:   mustParseShifts(t, ShiftConfig{Name: "global", Start: "00:00", End: "24:00", TZ: "Europe/Berlin"}),
+			now:      "2022-03-27T12:00:00Z",
+			current:  "global",
+			next:     "global",
+			handover: "2022-03-27T22:00:00Z",
+		},
+		{
+			name:     "single shift ending at its start",
+			shifts:   mustParseShifts(t, ShiftConfig{Name: "global", Start: "08:00", End: "08:00", TZ: "Europe/Berlin"}),
+			now:      "2022-03-26T12:00:00Z",
+			current:  "global",
+			next:     "global",
+			handover: "2022-03-27T06:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := mustParseTime(t, tt.now)
+
+			if current := GetShiftAt(tt.shifts, now); current.Name != tt.current {
+				t.Errorf("expected shift %s to be in charge, got %q", tt.current, current.Name)
+			}
+
+			next, handover, err := GetNextShift(tt.shifts, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if next.Name != tt.next {
+				t.Errorf("expected next shift %s, got %s", tt.next, next.Name)
+			}
+
+			if expected := mustParseTime(t, tt.handover); !handover.Equal(expected) {
+				t.Errorf("expected handover at %s, got %s", expected, handover.UTC())
+			}
+		})
+	}
+}
+
+func TestShiftOccurrences(t *testing.T) {
+	shift := mustParseShifts(t, ShiftConfig{Name: "night", Start: "22:00", End: "06:00"})[0]
+
+	tests := []struct {
+		name   string
+		at     string
+		active bool
+		start  string
+		last   string
+	}{
+		{name: "before midnight", at: "2022-11-07T23:00:00Z", active: true, start: "2022-11-07T22:00:00Z", last: "2022-11-06T22:00:00Z"},
+		{name: "after midnight", at: "2022-11-08T05:59:00Z", active: true, start: "2022-11-07T22:00:00Z", last: "2022-11-06T22:00:00Z"},
+		{name: "during the day", at: "2022-11-08T12:00:00Z", active: false, last: "2022-11-07T22:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := mustParseTime(t, tt.at)
+
+			occurrence, active := shift.OccurrenceAt(at)
+			if active != tt.active {
+				t.Fatalf("expected active to be %v, got %v", tt.active, active)
+			}
+
+			if active && !occurrence.Start.Equal(mustParseTime(t, tt.start)) {
+				t.Errorf("expected occurrence to start at %s, got %s", tt.start, occurrence.Start)
+			}
+
+			last, ok := shift.LastOccurrence(at)
+			if !ok || !last.Start.Equal(mustParseTime(t, tt.last)) {
+				t.Errorf("expected last occurrence to start at %s, got %s", tt.last, last.Start)
+			}
+		})
+	}
+}
+
+func TestWeekdayAndDateSpecificShifts(t *testing.T) {
+	shifts := mustParseShifts(t,
+		ShiftConfig{Name: "day", Start: "08:00", End: "20:00"},
+		ShiftConfig{Name: "night", Start: "20:00", End: "08:00"},
+		ShiftConfig{Name: "weekend", Start: "08:00", End: "08:00", Days: []string{"sat-sun"}},
+		ShiftConfig{Name: "holiday", Start: "00:00", End: "24:00", Dates: []string{"2022-12-26"}},
+	)
+
+	tests := []struct {
+		at       string
+		expected string
+	}{
+		{at: "2022-12-23T12:00:00Z", expected: "day"},
+		{at: "2022-12-23T22:00:00Z", expected: "night"},
+		{at: "2022-12-24T07:59:00Z", expected: "night"},
+		{at: "2022-12-24T08:00:00Z", expected: "weekend"},
+		{at: "2022-12-25T22:00:00Z", expected: "weekend"},
+		{at: "2022-12-26T00:00:00Z", expected: "holiday"},
+		{at: "2022-12-26T12:00:00Z", expected: "holiday"},
+		{at: "2022-12-27T00:00:00Z", expected: "night"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.at, func(t *testing.T) {
+			if shift := GetShiftAt(shifts, mustParseTime(t, tt.at)); shift.Name != tt.expected {
+				t.Errorf("expected shift %s to be in charge, got %q", tt.expected, shift.Name)
+			}
+		})
+	}
+
+	next, handover, err := GetNextShift(shifts, mustParseTime(t, "2022-12-23T12:00:00Z"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if next.Name != "night" || !handover.Equal(mustParseTime(t, "2022-12-23T20:00:00Z")) {
+		t.Errorf("expected handover to night at 20:00, got %s at %s", next.Name, handover)
+	}
+}
+
+func TestParseWeekdays(t *testing.T) {
+	tests := []struct {
+		days     []string
+		expected []time.Weekday
+		fails    bool
+	}{
+		{days: []string{"mon"}, expected: []time.Weekday{time.Monday}},
+		{days: []string{"Mon-Wed"}, expected: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday}},
+		{days: []string{"fri-mon"}, expected: []time.Weekday{time.Friday, time.Saturday, time.Sunday, time.Monday}},
+		{days: []string{"sat", "sun"}, expected: []time.Weekday{time.Saturday, time.Sunday}},
+		{days: []string{"monday"}, fails: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.days), func(t *testing.T) {
+			result, err := parseWeekdays(tt.days)
+			if tt.fails {
+				if err == nil {
+					t.Errorf("expected an error, got %v", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if fmt.Sprint(result) != fmt.Sprint(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestCheckShiftsReportsOverlaps(t *testing.T) {
+	shifts := mustParseShifts(t,
+		ShiftConfig{Name: "early", Start: "06:00", End: "15:00", Line: 3},
+		ShiftConfig{Name: "late", Start: "14:00", End: "06:00", Line: 6},
+		ShiftConfig{Name: "weekend", Start: "06:00", End: "06:00", Days: []string{"sat"}, Line: 9},
+	)
+
+	var errs []ShiftIssue
+	for _, issue := range checkShifts(shifts) {
+		if !issue.Warning {
+			errs = append(errs, issue)
+		}
+	}
+
+	if len(errs) != 1 || errs[0].Line != 6 {
+		t.Errorf("expected one overlap of the late shift, got %v", errs)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/gonvenience/wrap"
+)
+
+const minutesPerDay = 24 * 60
+
+// isoWeekdays maps the PagerDuty start_day_of_week (1 is Monday, 7 is Sunday) to weekday names
+var isoWeekdays = []string{"", "mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// ImportShifts reads the layers of the PagerDuty schedule and converts them
+// into equivalent shift-times entries
+func ImportShifts(ctx context.Context, client Client, scheduleID string) ([]ShiftConfig, error) {
+	schedule, err := client.GetScheduleWithContext(ctx, scheduleID, pagerduty.GetScheduleOptions{})
+	if err != nil {
+		return nil, wrap.Errorf(err, "failed to get schedule %s", scheduleID)
+	}
+
+	return ShiftsFromSchedule(schedule)
+}
+
+// ShiftsFromSchedule converts every layer of the schedule into one shift, or
+// into several numbered shifts if the layer has more than one restriction
+func ShiftsFromSchedule(schedule *pagerduty.Schedule) ([]ShiftConfig, error) {
+	var result []ShiftConfig
+	for _, layer := range schedule.ScheduleLayers {
+		var entries []ShiftConfig
+
+		if len(layer.Restrictions) == 0 {
+			entries = append(entries, ShiftConfig{Start: "00:00", End: "00:00"})
+		}
+
+		for _, restriction := range layer.Restrictions {
+			converted, err := shiftsFromRestriction(restriction)
+			if err != nil {
+				return nil, wrap.Errorf(err, "failed to convert layer %s", layer.Name)
+			}
+
+			entries = append(entries, converted...)
+		}
+
+		for i := range entries {
+			entries[i].Name = layer.Name
+			if len(entries) > 1 {
+				entries[i].Name = fmt.Sprintf("%s (%d)", layer.Name, i+1)
+			}
+
+			entries[i].TZ = schedule.TimeZone
+		}
+
+		result = append(result, entries...)
+	}
+
+	return result, nil
+}
+
+func shiftsFromRestriction(restriction pagerduty.Restriction) ([]ShiftConfig, error) {
+	if len(restriction.StartTimeOfDay) < 5 {
+		return nil, fmt.Errorf("unsupported start time of day %q", restriction.StartTimeOfDay)
+	}
+
+	start, err := parseShiftTime(restriction.StartTimeOfDay[:5])
+	if err != nil {
+		return nil, err
+	}
+
+	duration := int(restriction.DurationSeconds / 60)
+
+	switch restriction.Type {
+	case "daily_restriction":
+		if duration >= minutesPerDay {
+			return []ShiftConfig{{Start: start.String(), End: start.String()}}, nil
+		}
+
+		return []ShiftConfig{{Start: start.String(), End: ShiftTime((int(start) + duration) % minutesPerDay).String()}}, nil
+
+	case "weekly_restriction":
+		if restriction.StartDayOfWeek < 1 || restriction.StartDayOfWeek > 7 {
+			return nil, fmt.Errorf("unsupported start day of week %d", restriction.StartDayOfWeek)
+		}
+
+		var (
+			result   []ShiftConfig
+			fullDays = duration / minutesPerDay
+			rest     = duration % minutesPerDay
+			day      = int(restriction.StartDayOfWeek)
+		)
+
+		if fullDays > 0 {
+			var days []string
+			for i := 0; i < fullDays && i < 7; i++ {
+				days = append(days, isoWeekdays[(day+i-1)%7+1])
+			}
+
+			result = append(result, ShiftConfig{Start: start.String(), End: start.String(), Days: days})
+		}
+
+		if rest > 0 {
+			result = append(result, ShiftConfig{
+				Start: start.String(),
+				End:   ShiftTime((int(start) + rest) % minutesPerDay).String(),
+				Days:  []string{isoWeekdays[(day+fullDays-1)%7+1]},
+			})
+		}
+
+		return result, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported restriction type %q", strings.TrimSpace(restriction.Type))
+	}
+}
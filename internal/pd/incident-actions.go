@@ -0,0 +1,97 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"context"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+// IncidentResult is the outcome of an action on one incident
+type IncidentResult struct {
+	ID       string
+	Incident *pagerduty.Incident
+	Err      error
+}
+
+// ManageIncidents applies the change to each of the incidents on behalf of
+// the user with the from email address, every incident is changed with its
+// own request so that a failure does not affect the other incidents
+func ManageIncidents(ctx context.Context, client Client, from string, ids []string, change pagerduty.ManageIncidentsOptions) []IncidentResult {
+	return forEachIncident(ctx, ids, func(ctx context.Context, id string) (*pagerduty.Incident, error) {
+		options := change
+		options.ID = id
+
+		resp, err := client.ManageIncidentsWithContext(ctx, from, []pagerduty.ManageIncidentsOptions{options})
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range resp.Incidents {
+			if resp.Incidents[i].ID == id {
+				return &resp.Incidents[i], nil
+			}
+		}
+
+		return nil, nil
+	})
+}
+
+// SnoozeIncidents snoozes each of the incidents for the duration, which is
+// rounded to full seconds
+func SnoozeIncidents(ctx context.Context, client Client, ids []string, duration time.Duration) []IncidentResult {
+	return forEachIncident(ctx, ids, func(ctx context.Context, id string) (*pagerduty.Incident, error) {
+		return client.SnoozeIncidentWithContext(ctx, id, uint(duration.Round(time.Second).Seconds()))
+	})
+}
+
+// AddIncidentNotes adds a note with the content to each of the incidents on
+// behalf of the user with the from email address
+func AddIncidentNotes(ctx context.Context, client Client, from string, ids []string, content string) []IncidentResult {
+	return forEachIncident(ctx, ids, func(ctx context.Context, id string) (*pagerduty.Incident, error) {
+		_, err := client.CreateIncidentNoteWithContext(ctx, id, pagerduty.IncidentNote{
+			User:    pagerduty.APIObject{Summary: from},
+			Content: content,
+		})
+
+		return nil, err
+	})
+}
+
+// forEachIncident runs the action in parallel and collects the results in
+// the order of the IDs, a failing action does not stop the others
+func forEachIncident(ctx context.Context, ids []string, action func(ctx context.Context, id string) (*pagerduty.Incident, error)) []IncidentResult {
+	results, _ := ParallelMap(ctx, DefaultParallelism, ids, func(ctx context.Context, id string) (IncidentResult, error) {
+		incident, err := action(ctx, id)
+		return IncidentResult{ID: id, Incident: incident, Err: err}, nil
+	})
+
+	// incidents that were not processed because the context was cancelled
+	for i := range results {
+		if results[i].ID == "" {
+			results[i] = IncidentResult{ID: ids[i], Err: ctx.Err()}
+		}
+	}
+
+	return results
+}
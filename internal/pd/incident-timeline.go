@@ -0,0 +1,205 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/gonvenience/wrap"
+)
+
+// TimelineEvent is one step in the history of an incident, the type is the
+// log entry type without the _log_entry suffix, or note for incident notes
+type TimelineEvent struct {
+	Time    time.Time
+	Type    string
+	Actor   string
+	Summary string
+}
+
+// IncidentTimeline is the history of an incident, its log entries and notes
+// in chronological order
+type IncidentTimeline struct {
+	Incident     pagerduty.Incident
+	Events       []TimelineEvent
+	Triggered    time.Time
+	Acknowledged time.Time
+	Resolved     time.Time
+}
+
+// TimeToAcknowledge returns the time between the trigger and the first
+// acknowledgement, if the incident was acknowledged
+func (t *IncidentTimeline) TimeToAcknowledge() (time.Duration, bool) {
+	if t.Acknowledged.IsZero() {
+		return 0, false
+	}
+
+	return t.Acknowledged.Sub(t.Triggered), true
+}
+
+// TimeToResolve returns the time between the trigger and the resolution, if
+// the incident was resolved
+func (t *IncidentTimeline) TimeToResolve() (time.Duration, bool) {
+	if t.Resolved.IsZero() {
+		return 0, false
+	}
+
+	return t.Resolved.Sub(t.Triggered), true
+}
+
+// GetIncidentTimeline returns the incident with the given ID together with
+// its log entries and notes, the notes replace the annotate log entries
+// since they contain the text of the note
+func GetIncidentTimeline(ctx context.Context, client Client, id string) (*IncidentTimeline, error) {
+	incident, err := client.GetIncidentWithContext(ctx, id)
+	if err != nil {
+		return nil, wrap.Errorf(err, "failed to get incident %s", id)
+	}
+
+	logEntries, err := ListAllIncidentLogEntries(ctx, client, id, pagerduty.ListIncidentLogEntriesOptions{})
+	if err != nil {
+		return nil, wrap.Errorf(err, "failed to get log entries of incident %s", id)
+	}
+
+	notes, err := client.ListIncidentNotesWithContext(ctx, id)
+	if err != nil {
+		return nil, wrap.Errorf(err, "failed to get notes of incident %s", id)
+	}
+
+	timeline := IncidentTimeline{Incident: *incident}
+	if timeline.Triggered, err = ParseTimestamp(incident.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	for _, logEntry := range logEntries {
+		if logEntry.Type == "annotate_log_entry" {
+			continue
+		}
+
+		createdAt, err := ParseTimestamp(logEntry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		timeline.Events = append(timeline.Events, TimelineEvent{
+			Time:    createdAt,
+			Type:    strings.TrimSuffix(logEntry.Type, "_log_entry"),
+			Actor:   logEntryActor(logEntry),
+			Summary: logEntrySummary(logEntry),
+		})
+	}
+
+	var names = map[string]string{}
+	for _, note := range notes {
+		createdAt, err := ParseTimestamp(note.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		author := note.User.Summary
+		if author == "" && note.User.ID != "" {
+			if _, known := names[note.User.ID]; !known {
+				if user, err := client.GetUserWithContext(ctx, note.User.ID, pagerduty.GetUserOptions{}); err == nil {
+					names[note.User.ID] = user.Name
+				}
+			}
+
+			author = names[note.User.ID]
+		}
+
+		timeline.Events = append(timeline.Events, TimelineEvent{
+			Time:    createdAt,
+			Type:    "note",
+			Actor:   author,
+			Summary: note.Content,
+		})
+	}
+
+	sort.SliceStable(timeline.Events, func(i, j int) bool {
+		return timeline.Events[i].Time.Before(timeline.Events[j].Time)
+	})
+
+	for _, event := range timeline.Events {
+		switch event.Type {
+		case "acknowledge":
+			if timeline.Acknowledged.IsZero() {
+				timeline.Acknowledged = event.Time
+			}
+
+		case "resolve":
+			timeline.Resolved = event.Time
+		}
+	}
+
+	return &timeline, nil
+}
+
+// logEntryActor returns who carried out the step, for notifications this is
+// the notified user
+func logEntryActor(logEntry pagerduty.LogEntry) string {
+	if logEntry.Type == "notify_log_entry" && logEntry.User.Summary != "" {
+		return logEntry.User.Summary
+	}
+
+	return logEntry.Agent.Summary
+}
+
+// logEntrySummary returns the summary provided by PagerDuty, or a short
+// description based on the log entry type if there is none
+func logEntrySummary(logEntry pagerduty.LogEntry) string {
+	if logEntry.Summary != "" {
+		return logEntry.Summary
+	}
+
+	var assignees []string
+	for _, assignee := range logEntry.Assignees {
+		assignees = append(assignees, assignee.Summary)
+	}
+
+	var channel string
+	if logEntry.Channel.Type != "" {
+		channel = " via " + logEntry.Channel.Type
+	}
+
+	switch logEntry.Type {
+	case "trigger_log_entry":
+		return "Triggered" + channel
+
+	case "notify_log_entry":
+		return fmt.Sprintf("Notified %s%s", logEntry.User.Summary, channel)
+
+	case "assign_log_entry", "escalate_log_entry":
+		return fmt.Sprintf("Assigned to %s", strings.Join(assignees, ", "))
+
+	default:
+		event := strings.ReplaceAll(strings.TrimSuffix(logEntry.Type, "_log_entry"), "_", " ")
+		if event == "" {
+			return "Unknown event"
+		}
+
+		return strings.ToUpper(event[:1]) + event[1:]
+	}
+}
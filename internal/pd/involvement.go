@@ -0,0 +1,126 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+// Involvement describes how a user took part in an incident
+type Involvement string
+
+// Supported kinds of involvement, based on the incident log entry types
+const (
+	InvolvementAssigned     Involvement = "assigned"
+	InvolvementAcknowledged Involvement = "acknowledged"
+	InvolvementResolved     Involvement = "resolved"
+	InvolvementNotified     Involvement = "notified"
+)
+
+// Involvements lists all supported kinds of involvement
+var Involvements = []Involvement{
+	InvolvementAssigned,
+	InvolvementAcknowledged,
+	InvolvementResolved,
+	InvolvementNotified,
+}
+
+// ParseInvolvements parses the given names, no names select all kinds of involvement
+func ParseInvolvements(names []string) ([]Involvement, error) {
+	if len(names) == 0 {
+		return Involvements, nil
+	}
+
+	var result []Involvement
+	for _, name := range names {
+		involvement := Involvement(strings.ToLower(strings.TrimSpace(name)))
+		if !involvement.valid() {
+			var supported []string
+			for _, known := range Involvements {
+				supported = append(supported, string(known))
+			}
+
+			return nil, fmt.Errorf("unknown involvement %q, supported are: %s", name, strings.Join(supported, ", "))
+		}
+
+		result = append(result, involvement)
+	}
+
+	return result, nil
+}
+
+func (i Involvement) valid() bool {
+	for _, known := range Involvements {
+		if i == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsInvolved returns whether the log entries of an incident show that the
+// user with the given ID was involved in one of the given ways
+func IsInvolved(logEntries []pagerduty.LogEntry, userID string, involvements []Involvement) bool {
+	for _, logEntry := range logEntries {
+		for _, involvement := range involvements {
+			if involvement.matches(logEntry, userID) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matches checks the log entry using its type: assignments list the user as
+// assignee, acknowledgements and resolutions have the user as agent, and
+// notifications name the notified user
+func (i Involvement) matches(logEntry pagerduty.LogEntry, userID string) bool {
+	switch i {
+	case InvolvementAssigned:
+		switch logEntry.Type {
+		case "trigger_log_entry", "assign_log_entry", "escalate_log_entry":
+			for _, assignee := range logEntry.Assignees {
+				if assignee.ID == userID {
+					return true
+				}
+			}
+		}
+
+		return false
+
+	case InvolvementAcknowledged:
+		return logEntry.Type == "acknowledge_log_entry" && logEntry.Agent.ID == userID
+
+	case InvolvementResolved:
+		return logEntry.Type == "resolve_log_entry" && logEntry.Agent.ID == userID
+
+	case InvolvementNotified:
+		return logEntry.Type == "notify_log_entry" && logEntry.User.ID == userID
+
+	default:
+		return false
+	}
+}
@@ -22,8 +22,10 @@ package pd
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/PagerDuty/go-pagerduty"
@@ -37,71 +39,255 @@ type TimeRange struct {
 	End   time.Time
 }
 
+// Bounded returns the time range with an open (zero) start or end replaced
+// by the given bounds
+func (r TimeRange) Bounded(from time.Time, to time.Time) TimeRange {
+	if r.Start.IsZero() {
+		r.Start = from
+	}
+
+	if r.End.IsZero() {
+		r.End = to
+	}
+
+	return r
+}
+
 // CreatePagerDutyClient creates a new PagerDuty client based on the access
-// token stored in the ~/.pd.yml file
-func CreatePagerDutyClient() (*pagerduty.Client, error) {
-	config, err := loadConfig()
+// token stored in the ~/.pd.yml file, rate limited and failed requests are
+// retried with backoff
+func CreatePagerDutyClient() (Client, error) {
+	profile, err := loadProfile()
 	if err != nil {
 		return nil, err
 	}
 
-	return pagerduty.NewClient(config.Authtoken), nil
+	client := pagerduty.NewClient(profile.Authtoken)
+	client.HTTPClient = newRetryClient(client.HTTPClient)
+
+	return client, nil
+}
+
+// OnCallWindow is a continuous time range in which the user is on-call for
+// an escalation policy on a specific escalation level, permanent on-calls
+// have a zero start and end
+type OnCallWindow struct {
+	TimeRange
+	EscalationPolicy pagerduty.EscalationPolicy
+	EscalationLevel  uint
 }
 
-// GetPagerDutyOnCalls returns all currently active on-calls for the user
-func GetPagerDutyOnCalls(ctx context.Context, client *pagerduty.Client, user *pagerduty.User) (map[TimeRange]map[string]pagerduty.EscalationPolicy, error) {
-	list, err := GetAllOnCalls(ctx, client, user, "", "")
+// GetPagerDutyOnCalls returns all on-calls for the user in the given time range,
+// or the currently active on-calls if no time range is specified, adjacent and
+// overlapping on-calls of the same escalation policy and level are merged and
+// the result is sorted chronologically
+func GetPagerDutyOnCalls(ctx context.Context, client Client, user *pagerduty.User, start string, end string) ([]OnCallWindow, error) {
+	list, err := GetAllOnCalls(ctx, client, user, start, end)
 	if err != nil {
 		return nil, err
 	}
 
-	var oncalls = map[TimeRange]map[string]pagerduty.EscalationPolicy{}
-	for i := range list.OnCalls {
-		oncall := list.OnCalls[i]
-
-		start, err := parsePagerDutyTime(oncall.Start)
+	var windows = make([]OnCallWindow, 0, len(list))
+	for _, oncall := range list {
+		start, err := parseOnCallTimestamp(oncall.Start)
 		if err != nil {
 			return nil, err
 		}
 
-		end, err := parsePagerDutyTime(oncall.End)
+		end, err := parseOnCallTimestamp(oncall.End)
 		if err != nil {
 			return nil, err
 		}
 
-		timeRange := TimeRange{start, end}
+		windows = append(windows, OnCallWindow{
+			TimeRange:        TimeRange{start, end},
+			EscalationPolicy: oncall.EscalationPolicy,
+			EscalationLevel:  oncall.EscalationLevel,
+		})
+	}
+
+	return mergeOnCallWindows(windows), nil
+}
+
+// parseOnCallTimestamp parses the start or end of an on-call, which is empty
+// for permanent on-calls and results in a zero time
+func parseOnCallTimestamp(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	return ParseTimestamp(value)
+}
+
+// mergeOnCallWindows joins adjacent and overlapping windows of the same
+// escalation policy and level and sorts the result by start, end, escalation
+// policy, and level so that the order does not depend on the API response
+func mergeOnCallWindows(windows []OnCallWindow) []OnCallWindow {
+	sortOnCallWindows(windows)
+
+	var result []OnCallWindow
+	var open = map[string]int{}
+	for _, window := range windows {
+		key := fmt.Sprintf("%s/%d", window.EscalationPolicy.ID, window.EscalationLevel)
+		if i, found := open[key]; found && (result[i].End.IsZero() || !window.Start.After(result[i].End)) {
+			if window.End.IsZero() || (!result[i].End.IsZero() && window.End.After(result[i].End)) {
+				result[i].End = window.End
+			}
+
+			continue
+		}
+
+		open[key] = len(result)
+		result = append(result, window)
+	}
+
+	sortOnCallWindows(result)
+	return result
+}
+
+func sortOnCallWindows(windows []OnCallWindow) {
+	sort.SliceStable(windows, func(i, j int) bool {
+		a, b := windows[i], windows[j]
+		switch {
+		case !a.Start.Equal(b.Start):
+			return a.Start.Before(b.Start)
+
+		case !a.End.Equal(b.End):
+			return a.End.Before(b.End)
+
+		case a.EscalationPolicy.Summary != b.EscalationPolicy.Summary:
+			return a.EscalationPolicy.Summary < b.EscalationPolicy.Summary
+
+		case a.EscalationPolicy.ID != b.EscalationPolicy.ID:
+			return a.EscalationPolicy.ID < b.EscalationPolicy.ID
+
+		default:
+			return a.EscalationLevel < b.EscalationLevel
+		}
+	})
+}
+
+// OnCallSelector restricts on-calls to the given users, escalation policies,
+// and schedules, all on-calls are selected if no restriction is set
+type OnCallSelector struct {
+	UserIDs             []string
+	EscalationPolicyIDs []string
+	ScheduleIDs         []string
+}
+
+// OnCallPerson is an on-call entry together with the contact methods of the on-call user
+type OnCallPerson struct {
+	pagerduty.OnCall
+	ContactMethods []pagerduty.ContactMethod
+}
+
+// GetOnCallPersons returns all currently active on-calls matching the selector,
+// sorted by escalation policy, escalation level, and user name
+func GetOnCallPersons(ctx context.Context, client Client, selector OnCallSelector) ([]OnCallPerson, error) {
+	oncalls, err := ListAllOnCalls(
+		ctx,
+		client,
+		pagerduty.ListOnCallOptions{
+			UserIDs:             selector.UserIDs,
+			EscalationPolicyIDs: selector.EscalationPolicyIDs,
+			ScheduleIDs:         selector.ScheduleIDs,
+		})
+	if err != nil {
+		return nil, err
+	}
 
-		if _, found := oncalls[timeRange]; !found {
-			oncalls[timeRange] = map[string]pagerduty.EscalationPolicy{}
+	var users []pagerduty.User
+	var known = map[string]bool{}
+	for _, oncall := range oncalls {
+		if !known[oncall.User.ID] {
+			known[oncall.User.ID] = true
+			users = append(users, oncall.User)
 		}
+	}
 
-		oncalls[timeRange][oncall.EscalationPolicy.APIObject.ID] = oncall.EscalationPolicy
+	methods, errs := ParallelMap(ctx, DefaultParallelism, users, func(ctx context.Context, user pagerduty.User) ([]pagerduty.ContactMethod, error) {
+		resp, err := client.ListUserContactMethodsWithContext(ctx, user.ID)
+		if err != nil {
+			return nil, wrap.Errorf(err, "failed to get contact methods of user %s", user.Summary)
+		}
+
+		return resp.ContactMethods, nil
+	})
+	if len(errs) > 0 {
+		return nil, wrap.Errors(errs, "failed to get contact methods")
 	}
 
-	return oncalls, nil
+	var contactMethods = map[string][]pagerduty.ContactMethod{}
+	for i, user := range users {
+		contactMethods[user.ID] = methods[i]
+	}
+
+	var result = make([]OnCallPerson, len(oncalls))
+	for i, oncall := range oncalls {
+		result[i] = OnCallPerson{
+			OnCall:         oncall,
+			ContactMethods: contactMethods[oncall.User.ID],
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		switch {
+		case a.EscalationPolicy.Summary != b.EscalationPolicy.Summary:
+			return a.EscalationPolicy.Summary < b.EscalationPolicy.Summary
+
+		case a.EscalationLevel != b.EscalationLevel:
+			return a.EscalationLevel < b.EscalationLevel
+
+		default:
+			return a.User.Summary < b.User.Summary
+		}
+	})
+
+	return result, nil
 }
 
 // GetAllOnCalls returns all on calls for a specified user in a specified time range
 // If time range is not specified, only currently active on-calls will be returned
-func GetAllOnCalls(ctx context.Context, client *pagerduty.Client, user *pagerduty.User, start string, end string) (*pagerduty.ListOnCallsResponse, error) {
-	return client.ListOnCallsWithContext(
+func GetAllOnCalls(ctx context.Context, client Client, user *pagerduty.User, start string, end string) ([]pagerduty.OnCall, error) {
+	return ListAllOnCalls(
 		ctx,
+		client,
 		pagerduty.ListOnCallOptions{
-			Limit:    100,
 			UserIDs:  []string{user.ID},
 			Since:    start,
 			Until:    end,
-			Earliest: true,
+			Earliest: start == "" && end == "",
 		})
 }
 
 // GetTemplate returns the requested template
 func GetTemplate(templateName string) (string, error) {
-	config, err := loadConfig()
+	profile, err := loadProfile()
 	if err != nil {
 		return "", err
 	}
-	return config.Templates[templateName], err
+	return profile.Templates[templateName], err
+}
+
+// GetTestPageService returns the configured service for test pages, which
+// is empty if none is configured
+func GetTestPageService() (string, error) {
+	profile, err := loadProfile()
+	if err != nil {
+		return "", err
+	}
+
+	return profile.TestPageService, nil
+}
+
+func loadProfile() (*Profile, error) {
+	config, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return config.activeProfile()
 }
 
 func loadConfig() (*Config, error) {
@@ -131,7 +317,3 @@ func getDataFromYAMLFile() ([]byte, error) {
 	}
 	return data, nil
 }
-
-func parsePagerDutyTime(input string) (time.Time, error) {
-	return time.Parse("2006-01-02T15:04:05Z", input)
-}
@@ -0,0 +1,121 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+func TestGetPagerDutyOnCalls(t *testing.T) {
+	var (
+		user     = pagerduty.User{APIObject: pagerduty.APIObject{ID: "PUSER01"}}
+		platform = pagerduty.EscalationPolicy{APIObject: pagerduty.APIObject{ID: "PPLATFO", Summary: "Platform"}}
+		database = pagerduty.EscalationPolicy{APIObject: pagerduty.APIObject{ID: "PDATABA", Summary: "Database"}}
+	)
+
+	oncall := func(policy pagerduty.EscalationPolicy, start string, end string) pagerduty.OnCall {
+		return pagerduty.OnCall{User: user, EscalationPolicy: policy, EscalationLevel: 1, Start: start, End: end}
+	}
+
+	tests := []struct {
+		name     string
+		oncalls  []pagerduty.OnCall
+		expected []TimeRange
+	}{
+		{
+			name: "adjacent on-calls are merged",
+			oncalls: []pagerduty.OnCall{
+				oncall(platform, "2022-11-08T08:00:00Z", "2022-11-08T16:00:00Z"),
+				oncall(platform, "2022-11-07T08:00:00Z", "2022-11-08T08:00:00Z"),
+			},
+			expected: []TimeRange{
+				{Start: mustParseTime(t, "2022-11-07T08:00:00Z"), End: mustParseTime(t, "2022-11-08T16:00:00Z")},
+			},
+		},
+		{
+			name: "on-calls of other policies are kept",
+			oncalls: []pagerduty.OnCall{
+				oncall(platform, "2022-11-07T08:00:00Z", "2022-11-07T16:00:00Z"),
+				oncall(database, "2022-11-07T12:00:00Z", "2022-11-07T20:00:00Z"),
+			},
+			expected: []TimeRange{
+				{Start: mustParseTime(t, "2022-11-07T08:00:00Z"), End: mustParseTime(t, "2022-11-07T16:00:00Z")},
+				{Start: mustParseTime(t, "2022-11-07T12:00:00Z"), End: mustParseTime(t, "2022-11-07T20:00:00Z")},
+			},
+		},
+		{
+			name: "permanent on-calls are open-ended",
+			oncalls: []pagerduty.OnCall{
+				oncall(database, "", ""),
+			},
+			expected: []TimeRange{{}},
+		},
+		{
+			name: "permanent on-calls absorb windows of the same policy",
+			oncalls: []pagerduty.OnCall{
+				oncall(platform, "2022-11-07T08:00:00Z", "2022-11-07T16:00:00Z"),
+				oncall(platform, "", ""),
+			},
+			expected: []TimeRange{{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &FakeClient{OnCalls: tt.oncalls}
+
+			windows, err := GetPagerDutyOnCalls(context.Background(), client, &user, "", "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(windows) != len(tt.expected) {
+				t.Fatalf("expected %d windows, got %v", len(tt.expected), windows)
+			}
+
+			for i, window := range windows {
+				if !window.Start.Equal(tt.expected[i].Start) || !window.End.Equal(tt.expected[i].End) {
+					t.Errorf("expected window %d to be %v, got %v", i, tt.expected[i], window.TimeRange)
+				}
+			}
+		})
+	}
+}
+
+func TestTimeRangeBounded(t *testing.T) {
+	var (
+		from = time.Date(2022, 11, 7, 0, 0, 0, 0, time.UTC)
+		to   = from.AddDate(0, 0, 7)
+		mid  = from.AddDate(0, 0, 3)
+	)
+
+	if bounded := (TimeRange{}).Bounded(from, to); !bounded.Start.Equal(from) || !bounded.End.Equal(to) {
+		t.Errorf("expected open time range to be bounded, got %v", bounded)
+	}
+
+	if bounded := (TimeRange{Start: mid}).Bounded(from, to); !bounded.Start.Equal(mid) || !bounded.End.Equal(to) {
+		t.Errorf("expected only the open end to be bounded, got %v", bounded)
+	}
+}
@@ -21,8 +21,11 @@
 package pd
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -30,21 +33,110 @@ import (
 // Tip: Check https://yaml.to-go.online/ or https://mholt.github.io/json-to-go/
 // for an easy way to translate YAML or JSON files into Go struct code.
 
-// Config describes the pd tool configuration structure
+// ProfileEnvVar is the environment variable that can be used to select the
+// configuration profile, the --profile flag takes precedence over it
+const ProfileEnvVar = "PD_PROFILE"
+
+// selectedProfile is the profile name that was explicitly requested
+var selectedProfile string
+
+// Config describes the pd tool configuration structure, the top-level
+// profile settings are used when no named profiles are configured
 type Config struct {
-	Authtoken  string `yaml:"authtoken"`
-	OwnShift   string `yaml:"own-shift"`
-	ShiftTimes []struct {
-		Name  string `yaml:"name"`
-		Start string `yaml:"start"`
-		End   string `yaml:"end"`
-	} `yaml:"shift-times"`
+	Profile `yaml:",inline"`
+
+	DefaultProfile string             `yaml:"default-profile"`
+	Profiles       map[string]Profile `yaml:"profiles"`
+}
+
+// Profile describes the settings for one PagerDuty account
+type Profile struct {
+	Authtoken  string        `yaml:"authtoken"`
+	OwnShift   string        `yaml:"own-shift"`
+	ShiftTimes []ShiftConfig `yaml:"shift-times"`
 
 	Templates map[string]string `yaml:"templates"`
+
+	TestPageService string `yaml:"test-page-service"`
+}
+
+// ShiftConfig describes one entry of the shift-times list, start and end
+// are local times in the IANA time zone tz (UTC if not set), days and dates
+// restrict the shift to start only on these weekdays or calendar dates
+type ShiftConfig struct {
+	Name  string   `yaml:"name" json:"name"`
+	Start string   `yaml:"start" json:"start"`
+	End   string   `yaml:"end" json:"end"`
+	TZ    string   `yaml:"tz,omitempty" json:"tz,omitempty"`
+	Days  []string `yaml:"days,omitempty,flow" json:"days,omitempty"`
+	Dates []string `yaml:"dates,omitempty,flow" json:"dates,omitempty"`
+
+	Line int `yaml:"-" json:"-"`
+}
+
+// UnmarshalYAML decodes the shift entry and records its line in the file
+func (c *ShiftConfig) UnmarshalYAML(node *yaml.Node) error {
+	type plain ShiftConfig
+	if err := node.Decode((*plain)(c)); err != nil {
+		return err
+	}
+
+	c.Line = node.Line
+	return nil
+}
+
+// SelectProfile sets the name of the profile to be used, an empty name
+// falls back to the PD_PROFILE environment variable or the default profile
+func SelectProfile(name string) {
+	selectedProfile = name
+}
+
+// profileName returns the name of the profile to be used, or an empty
+// string if the top-level settings are to be used
+func (c *Config) profileName() string {
+	switch {
+	case selectedProfile != "":
+		return selectedProfile
+
+	case os.Getenv(ProfileEnvVar) != "":
+		return os.Getenv(ProfileEnvVar)
+
+	default:
+		return c.DefaultProfile
+	}
 }
 
-// ChangeYAMLFile changes a specific value in the .pd.yml file
-func ChangeYAMLFile(name string, newValue string) error {
+// activeProfile returns the settings of the selected profile
+func (c *Config) activeProfile() (*Profile, error) {
+	name := c.profileName()
+	if name == "" {
+		if c.Authtoken == "" && len(c.Profiles) > 0 {
+			return nil, fmt.Errorf("no profile selected, please use --profile, %s, or set default-profile in the .pd.yml file to one of: %s", ProfileEnvVar, strings.Join(c.profileNames(), ", "))
+		}
+
+		return &c.Profile, nil
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q is not configured in the .pd.yml file, available profiles are: %s", name, strings.Join(c.profileNames(), ", "))
+	}
+
+	return &profile, nil
+}
+
+func (c *Config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// ChangeYAMLFile changes a specific value of the selected profile in the .pd.yml file
+func ChangeYAMLFile(name string, newValue interface{}) error {
 
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -56,12 +148,33 @@ func ChangeYAMLFile(name string, newValue string) error {
 		return err
 	}
 
-	config := make(map[interface{}]interface{})
+	var parsed Config
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+
+	if _, err := parsed.activeProfile(); err != nil {
+		return err
+	}
+
+	config := make(map[string]interface{})
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return err
 	}
 
-	config[name] = newValue
+	if profileName := parsed.profileName(); profileName != "" {
+		profiles, _ := config["profiles"].(map[string]interface{})
+		profile, _ := profiles[profileName].(map[string]interface{})
+		if profile == nil {
+			profile = make(map[string]interface{})
+			profiles[profileName] = profile
+		}
+
+		profile[name] = newValue
+
+	} else {
+		config[name] = newValue
+	}
 
 	d, err := yaml.Marshal(&config)
 	if err != nil {
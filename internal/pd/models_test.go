@@ -0,0 +1,209 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const profilesConfig = `default-profile: work
+profiles:
+  work:
+    authtoken: work-token
+    own-shift: EMEA
+  private:
+    authtoken: private-token
+    own-shift: AMER
+`
+
+// writeConfig writes the .pd.yml file into a temporary home directory
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	home := t.TempDir()
+	path := filepath.Join(home, ".pd.yml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HOME", home)
+	t.Cleanup(func() { SelectProfile("") })
+
+	return path
+}
+
+func TestProfileSelection(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		flag      string
+		env       string
+		authtoken string
+		err       string
+	}{
+		{
+			name:      "default profile",
+			config:    profilesConfig,
+			authtoken: "work-token",
+		},
+		{
+			name:      "environment variable before default profile",
+			config:    profilesConfig,
+			env:       "private",
+			authtoken: "private-token",
+		},
+		{
+			name:      "flag before environment variable",
+			config:    profilesConfig,
+			flag:      "work",
+			env:       "private",
+			authtoken: "work-token",
+		},
+		{
+			name:      "flag before default profile",
+			config:    profilesConfig,
+			flag:      "private",
+			authtoken: "private-token",
+		},
+		{
+			name:   "unknown profile",
+			config: profilesConfig,
+			flag:   "unknown",
+			err:    `profile "unknown" is not configured in the .pd.yml file, available profiles are: private, work`,
+		},
+		{
+			name:   "no profile selected",
+			config: strings.Replace(profilesConfig, "default-profile: work\n", "", 1),
+			err:    "no profile selected",
+		},
+		{
+			name:      "top-level settings without profiles",
+			config:    "authtoken: top-level-token\n",
+			authtoken: "top-level-token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfig(t, tt.config)
+			t.Setenv(ProfileEnvVar, tt.env)
+			SelectProfile(tt.flag)
+
+			profile, err := loadProfile()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if profile.Authtoken != tt.authtoken {
+				t.Errorf("expected authtoken %q, got %q", tt.authtoken, profile.Authtoken)
+			}
+		})
+	}
+}
+
+func TestChangeYAMLFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		flag     string
+		expected Config
+	}{
+		{
+			name:   "selected profile",
+			config: profilesConfig,
+			flag:   "private",
+			expected: Config{
+				DefaultProfile: "work",
+				Profiles: map[string]Profile{
+					"work":    {Authtoken: "work-token", OwnShift: "EMEA"},
+					"private": {Authtoken: "private-token", OwnShift: "APAC"},
+				},
+			},
+		},
+		{
+			name:   "default profile",
+			config: profilesConfig,
+			expected: Config{
+				DefaultProfile: "work",
+				Profiles: map[string]Profile{
+					"work":    {Authtoken: "work-token", OwnShift: "APAC"},
+					"private": {Authtoken: "private-token", OwnShift: "AMER"},
+				},
+			},
+		},
+		{
+			name:   "top-level settings without profiles",
+			config: "authtoken: top-level-token\nown-shift: EMEA\n",
+			expected: Config{
+				Profile: Profile{Authtoken: "top-level-token", OwnShift: "APAC"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, tt.config)
+			t.Setenv(ProfileEnvVar, "")
+			SelectProfile(tt.flag)
+
+			if err := ChangeYAMLFile("own-shift", "APAC"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var config Config
+			if err := yaml.Unmarshal(data, &config); err != nil {
+				t.Fatal(err)
+			}
+
+			if config.Authtoken != tt.expected.Authtoken || config.OwnShift != tt.expected.OwnShift || config.DefaultProfile != tt.expected.DefaultProfile {
+				t.Errorf("expected top-level settings %+v, got %+v", tt.expected, config)
+			}
+
+			if len(config.Profiles) != len(tt.expected.Profiles) {
+				t.Fatalf("expected profiles %+v, got %+v", tt.expected.Profiles, config.Profiles)
+			}
+
+			for name, expected := range tt.expected.Profiles {
+				if actual := config.Profiles[name]; actual.Authtoken != expected.Authtoken || actual.OwnShift != expected.OwnShift {
+					t.Errorf("expected profile %s to be %+v, got %+v", name, expected, actual)
+				}
+			}
+		})
+	}
+}
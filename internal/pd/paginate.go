@@ -0,0 +1,216 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/gonvenience/bunt"
+)
+
+// pageSize is the largest page size the PagerDuty API accepts
+const pageSize = 100
+
+// DefaultLimit is the number of entries after which the listing helpers stop,
+// it matches the largest offset the PagerDuty API supports
+const DefaultLimit = 10000
+
+var (
+	listLimit               = DefaultLimit
+	warningOutput io.Writer = os.Stderr
+)
+
+// SetLimit sets the maximum number of entries the listing helpers collect,
+// a limit of zero or less resets it to the default limit
+func SetLimit(limit int) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	listLimit = limit
+}
+
+// SetWarningOutput sets the writer used to report that a listing was cut
+// short because the limit was reached
+func SetWarningOutput(w io.Writer) {
+	warningOutput = w
+}
+
+// ListAllOnCalls returns all on-calls matching the options, following the
+// pagination of the PagerDuty API up to the configured limit
+func ListAllOnCalls(ctx context.Context, client Client, o pagerduty.ListOnCallOptions) ([]pagerduty.OnCall, error) {
+	return listAll(ctx, "on-calls", func(offset uint, limit uint) ([]pagerduty.OnCall, pagerduty.APIListObject, error) {
+		o.Offset, o.Limit = offset, limit
+		resp, err := client.ListOnCallsWithContext(ctx, o)
+		if err != nil {
+			return nil, pagerduty.APIListObject{}, err
+		}
+
+		return resp.OnCalls, resp.APIListObject, nil
+	})
+}
+
+// ListAllIncidents returns all incidents matching the options, following the
+// pagination of the PagerDuty API up to the configured limit
+func ListAllIncidents(ctx context.Context, client Client, o pagerduty.ListIncidentsOptions) ([]pagerduty.Incident, error) {
+	return listAll(ctx, "incidents", func(offset uint, limit uint) ([]pagerduty.Incident, pagerduty.APIListObject, error) {
+		o.Offset, o.Limit = offset, limit
+		resp, err := client.ListIncidentsWithContext(ctx, o)
+		if err != nil {
+			return nil, pagerduty.APIListObject{}, err
+		}
+
+		return resp.Incidents, resp.APIListObject, nil
+	})
+}
+
+// ListAllUsers returns all users matching the options, following the
+// pagination of the PagerDuty API up to the configured limit
+func ListAllUsers(ctx context.Context, client Client, o pagerduty.ListUsersOptions) ([]pagerduty.User, error) {
+	return listAll(ctx, "users", func(offset uint, limit uint) ([]pagerduty.User, pagerduty.APIListObject, error) {
+		o.Offset, o.Limit = offset, limit
+		resp, err := client.ListUsersWithContext(ctx, o)
+		if err != nil {
+			return nil, pagerduty.APIListObject{}, err
+		}
+
+		return resp.Users, resp.APIListObject, nil
+	})
+}
+
+// ListAllTeams returns all teams matching the options, following the
+// pagination of the PagerDuty API up to the configured limit
+func ListAllTeams(ctx context.Context, client Client, o pagerduty.ListTeamOptions) ([]pagerduty.Team, error) {
+	return listAll(ctx, "teams", func(offset uint, limit uint) ([]pagerduty.Team, pagerduty.APIListObject, error) {
+		o.Offset, o.Limit = offset, limit
+		resp, err := client.ListTeamsWithContext(ctx, o)
+		if err != nil {
+			return nil, pagerduty.APIListObject{}, err
+		}
+
+		return resp.Teams, resp.APIListObject, nil
+	})
+}
+
+// ListAllSchedules returns all schedules matching the options, following the
+// pagination of the PagerDuty API up to the configured limit
+func ListAllSchedules(ctx context.Context, client Client, o pagerduty.ListSchedulesOptions) ([]pagerduty.Schedule, error) {
+	return listAll(ctx, "schedules", func(offset uint, limit uint) ([]pagerduty.Schedule, pagerduty.APIListObject, error) {
+		o.Offset, o.Limit = offset, limit
+		resp, err := client.ListSchedulesWithContext(ctx, o)
+		if err != nil {
+			return nil, pagerduty.APIListObject{}, err
+		}
+
+		return resp.Schedules, resp.APIListObject, nil
+	})
+}
+
+// ListAllEscalationPolicies returns all escalation policies matching the
+// options, following the pagination of the PagerDuty API up to the configured limit
+func ListAllEscalationPolicies(ctx context.Context, client Client, o pagerduty.ListEscalationPoliciesOptions) ([]pagerduty.EscalationPolicy, error) {
+	return listAll(ctx, "escalation policies", func(offset uint, limit uint) ([]pagerduty.EscalationPolicy, pagerduty.APIListObject, error) {
+		o.Offset, o.Limit = offset, limit
+		resp, err := client.ListEscalationPoliciesWithContext(ctx, o)
+		if err != nil {
+			return nil, pagerduty.APIListObject{}, err
+		}
+
+		return resp.EscalationPolicies, resp.APIListObject, nil
+	})
+}
+
+// ListAllServices returns all services matching the options, following the
+// pagination of the PagerDuty API up to the configured limit
+func ListAllServices(ctx context.Context, client Client, o pagerduty.ListServiceOptions) ([]pagerduty.Service, error) {
+	return listAll(ctx, "services", func(offset uint, limit uint) ([]pagerduty.Service, pagerduty.APIListObject, error) {
+		o.Offset, o.Limit = offset, limit
+		resp, err := client.ListServicesWithContext(ctx, o)
+		if err != nil {
+			return nil, pagerduty.APIListObject{}, err
+		}
+
+		return resp.Services, resp.APIListObject, nil
+	})
+}
+
+// ListAllIncidentLogEntries returns all log entries of the incident, following
+// the pagination of the PagerDuty API up to the configured limit
+func ListAllIncidentLogEntries(ctx context.Context, client Client, incidentID string, o pagerduty.ListIncidentLogEntriesOptions) ([]pagerduty.LogEntry, error) {
+	return listAll(ctx, "log entries", func(offset uint, limit uint) ([]pagerduty.LogEntry, pagerduty.APIListObject, error) {
+		o.Offset, o.Limit = offset, limit
+		resp, err := client.ListIncidentLogEntriesWithContext(ctx, incidentID, o)
+		if err != nil {
+			return nil, pagerduty.APIListObject{}, err
+		}
+
+		return resp.LogEntries, resp.APIListObject, nil
+	})
+}
+
+// ListAllLogEntries returns the log entries of all incidents matching the
+// options, following the pagination of the PagerDuty API up to the configured limit
+func ListAllLogEntries(ctx context.Context, client Client, o pagerduty.ListLogEntriesOptions) ([]pagerduty.LogEntry, error) {
+	return listAll(ctx, "log entries", func(offset uint, limit uint) ([]pagerduty.LogEntry, pagerduty.APIListObject, error) {
+		o.Offset, o.Limit = offset, limit
+		resp, err := client.ListLogEntriesWithContext(ctx, o)
+		if err != nil {
+			return nil, pagerduty.APIListObject{}, err
+		}
+
+		return resp.LogEntries, resp.APIListObject, nil
+	})
+}
+
+// listAll requests pages using fetch until there are no more entries, the
+// context is cancelled, or the limit is reached, which results in a warning
+func listAll[T any](ctx context.Context, what string, fetch func(offset uint, limit uint) ([]T, pagerduty.APIListObject, error)) ([]T, error) {
+	var result []T
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		size := listLimit - len(result)
+		if size > pageSize {
+			size = pageSize
+		}
+
+		page, list, err := fetch(uint(len(result)), uint(size))
+		if err != nil {
+			return result, err
+		}
+
+		result = append(result, page...)
+
+		switch {
+		case !list.More || len(page) == 0:
+			return result, nil
+
+		case len(result) >= listLimit:
+			bunt.Fprintf(warningOutput, "Gold{*Warning:*} stopped after *%d* %s, there are more (use --limit to raise the limit)\n", len(result), what)
+			return result, nil
+		}
+	}
+}
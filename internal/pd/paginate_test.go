@@ -0,0 +1,118 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+// pageRecorder records the offset and limit of all requested incident pages
+type pageRecorder struct {
+	*FakeClient
+	pages []string
+}
+
+func (r *pageRecorder) ListIncidentsWithContext(ctx context.Context, o pagerduty.ListIncidentsOptions) (*pagerduty.ListIncidentsResponse, error) {
+	r.pages = append(r.pages, fmt.Sprintf("%d+%d", o.Offset, o.Limit))
+	return r.FakeClient.ListIncidentsWithContext(ctx, o)
+}
+
+func TestListAllStopsAtLimit(t *testing.T) {
+	var incidents []pagerduty.Incident
+	for i := 0; i < 250; i++ {
+		incidents = append(incidents, pagerduty.Incident{APIObject: pagerduty.APIObject{ID: fmt.Sprintf("PINC%03d", i)}})
+	}
+
+	tests := []struct {
+		name    string
+		limit   int
+		count   int
+		pages   []string
+		warning string
+	}{
+		{name: "default limit", limit: 0, count: 250, pages: []string{"0+100", "100+100", "200+100"}},
+		{name: "limit within the first page", limit: 50, count: 50, pages: []string{"0+50"}, warning: "Warning: stopped after 50 incidents, there are more (use --limit to raise the limit)"},
+		{name: "limit within a later page", limit: 120, count: 120, pages: []string{"0+100", "100+20"}, warning: "Warning: stopped after 120 incidents"},
+		{name: "limit matching the number of incidents", limit: 250, count: 250, pages: []string{"0+100", "100+100", "200+50"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warnings bytes.Buffer
+			SetWarningOutput(&warnings)
+			SetLimit(tt.limit)
+			t.Cleanup(func() {
+				SetWarningOutput(os.Stderr)
+				SetLimit(0)
+			})
+
+			client := &pageRecorder{FakeClient: &FakeClient{Incidents: incidents}}
+			result, err := ListAllIncidents(context.Background(), client, pagerduty.ListIncidentsOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(result) != tt.count {
+				t.Errorf("expected %d incidents, got %d", tt.count, len(result))
+			}
+
+			if !equalStrings(client.pages, tt.pages) {
+				t.Errorf("expected pages %v, got %v", tt.pages, client.pages)
+			}
+
+			switch {
+			case tt.warning == "" && warnings.Len() > 0:
+				t.Errorf("expected no warning, got %q", warnings.String())
+
+			case !strings.Contains(warnings.String(), tt.warning):
+				t.Errorf("expected warning %q, got %q", tt.warning, warnings.String())
+			}
+		})
+	}
+}
+
+func TestSetLimit(t *testing.T) {
+	t.Cleanup(func() { SetLimit(0) })
+
+	tests := []struct {
+		limit    int
+		expected int
+	}{
+		{limit: 5, expected: 5},
+		{limit: 0, expected: DefaultLimit},
+		{limit: 20000, expected: 20000},
+		{limit: -1, expected: DefaultLimit},
+	}
+
+	for _, tt := range tests {
+		SetLimit(tt.limit)
+		if listLimit != tt.expected {
+			t.Errorf("expected limit %d after SetLimit(%d), got %d", tt.expected, tt.limit, listLimit)
+		}
+	}
+}
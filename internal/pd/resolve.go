@@ -0,0 +1,201 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/gonvenience/wrap"
+)
+
+// idPattern matches the format of PagerDuty object IDs
+var idPattern = regexp.MustCompile(`^P[A-Z0-9]{6}$`)
+
+// ResolveUser returns the user with the given ID, email address, or (part of
+// the) name, the value "me" refers to the user of the authtoken
+func ResolveUser(ctx context.Context, client Client, value string) (*pagerduty.User, error) {
+	if value == "me" {
+		return client.GetCurrentUserWithContext(ctx, pagerduty.GetCurrentUserOptions{})
+	}
+
+	if idPattern.MatchString(value) {
+		if user, err := client.GetUserWithContext(ctx, value, pagerduty.GetUserOptions{}); err == nil {
+			return user, nil
+		}
+	}
+
+	users, err := ListAllUsers(ctx, client, pagerduty.ListUsersOptions{Query: value})
+	if err != nil {
+		return nil, wrap.Errorf(err, "failed to look up user %s", value)
+	}
+
+	var names []string
+	for i := range users {
+		names = append(names, users[i].Name)
+	}
+
+	pos, err := pick("user", value, names, func(i int) bool {
+		return strings.EqualFold(users[i].Email, value)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &users[pos], nil
+}
+
+// ResolveTeam returns the team with the given ID or (part of the) name
+func ResolveTeam(ctx context.Context, client Client, value string) (*pagerduty.Team, error) {
+	teams, err := ListAllTeams(ctx, client, pagerduty.ListTeamOptions{Query: queryFor(value)})
+	if err != nil {
+		return nil, wrap.Errorf(err, "failed to look up team %s", value)
+	}
+
+	var names []string
+	for i := range teams {
+		names = append(names, teams[i].Name)
+	}
+
+	pos, err := pick("team", value, names, func(i int) bool {
+		return teams[i].ID == value
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &teams[pos], nil
+}
+
+// ResolveSchedule returns the schedule with the given ID or (part of the) name
+func ResolveSchedule(ctx context.Context, client Client, value string) (*pagerduty.Schedule, error) {
+	if idPattern.MatchString(value) {
+		if schedule, err := client.GetScheduleWithContext(ctx, value, pagerduty.GetScheduleOptions{}); err == nil {
+			return schedule, nil
+		}
+	}
+
+	schedules, err := ListAllSchedules(ctx, client, pagerduty.ListSchedulesOptions{Query: value})
+	if err != nil {
+		return nil, wrap.Errorf(err, "failed to look up schedule %s", value)
+	}
+
+	var names []string
+	for i := range schedules {
+		names = append(names, schedules[i].Name)
+	}
+
+	pos, err := pick("schedule", value, names, func(int) bool { return false })
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedules[pos], nil
+}
+
+// ResolveEscalationPolicy returns the escalation policy with the given ID or (part of the) name
+func ResolveEscalationPolicy(ctx context.Context, client Client, value string) (*pagerduty.EscalationPolicy, error) {
+	policies, err := ListAllEscalationPolicies(ctx, client, pagerduty.ListEscalationPoliciesOptions{Query: queryFor(value)})
+	if err != nil {
+		return nil, wrap.Errorf(err, "failed to look up escalation policy %s", value)
+	}
+
+	var names []string
+	for i := range policies {
+		names = append(names, policies[i].Name)
+	}
+
+	pos, err := pick("escalation policy", value, names, func(i int) bool {
+		return policies[i].ID == value
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &policies[pos], nil
+}
+
+// ResolveService returns the service with the given ID or (part of the) name
+func ResolveService(ctx context.Context, client Client, value string) (*pagerduty.Service, error) {
+	services, err := ListAllServices(ctx, client, pagerduty.ListServiceOptions{Query: queryFor(value)})
+	if err != nil {
+		return nil, wrap.Errorf(err, "failed to look up service %s", value)
+	}
+
+	var names []string
+	for i := range services {
+		names = append(names, services[i].Name)
+	}
+
+	pos, err := pick("service", value, names, func(i int) bool {
+		return services[i].ID == value
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &services[pos], nil
+}
+
+// queryFor returns the search query for the value, IDs cannot be searched
+// for, so all objects are listed page by page instead
+func queryFor(value string) string {
+	if idPattern.MatchString(value) {
+		return ""
+	}
+
+	return value
+}
+
+// pick selects the single match out of the candidates found by the search
+// query, in case of multiple candidates a preferred or exact (case-insensitive)
+// name match is used
+func pick(kind string, value string, names []string, preferred func(int) bool) (int, error) {
+	for i := range names {
+		if preferred(i) {
+			return i, nil
+		}
+	}
+
+	if idPattern.MatchString(value) {
+		return -1, fmt.Errorf("there is no %s with ID %s", kind, value)
+	}
+
+	for i, name := range names {
+		if strings.EqualFold(name, value) {
+			return i, nil
+		}
+	}
+
+	switch len(names) {
+	case 0:
+		return -1, fmt.Errorf("there is no %s matching %q", kind, value)
+
+	case 1:
+		return 0, nil
+
+	default:
+		return -1, fmt.Errorf("%s %q is ambiguous, it matches: %s", kind, value, strings.Join(names, ", "))
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+func TestResolveBeyondFirstPage(t *testing.T) {
+	client := &FakeClient{}
+	for i := 0; i < 150; i++ {
+		id := fmt.Sprintf("P%06d", i)
+		client.Teams = append(client.Teams, pagerduty.Team{APIObject: pagerduty.APIObject{ID: id}, Name: fmt.Sprintf("Team %03d", i)})
+		client.Services = append(client.Services, pagerduty.Service{APIObject: pagerduty.APIObject{ID: id}, Name: fmt.Sprintf("Service %03d", i)})
+		client.Policies = append(client.Policies, pagerduty.EscalationPolicy{APIObject: pagerduty.APIObject{ID: id}, Name: fmt.Sprintf("Policy %03d", i)})
+	}
+
+	tests := []struct {
+		name     string
+		resolve  func(value string) (string, error)
+		value    string
+		expected string
+		fails    bool
+	}{
+		{
+			name:     "team by ID",
+			resolve:  resolvedTeamID(client),
+			value:    "P000130",
+			expected: "P000130",
+		},
+		{
+			name:     "team by name",
+			resolve:  resolvedTeamID(client),
+			value:    "team 142",
+			expected: "P000142",
+		},
+		{
+			name:    "unknown team ID",
+			resolve: resolvedTeamID(client),
+			value:   "P999999",
+			fails:   true,
+		},
+		{
+			name: "escalation policy by ID",
+			resolve: func(value string) (string, error) {
+				policy, err := ResolveEscalationPolicy(context.Background(), client, value)
+				if err != nil {
+					return "", err
+				}
+
+				return policy.ID, nil
+			},
+			value:    "P000149",
+			expected: "P000149",
+		},
+		{
+			name: "service by ID",
+			resolve: func(value string) (string, error) {
+				service, err := ResolveService(context.Background(), client, value)
+				if err != nil {
+					return "", err
+				}
+
+				return service.ID, nil
+			},
+			value:    "P000120",
+			expected: "P000120",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := tt.resolve(tt.value)
+			if tt.fails {
+				if err == nil {
+					t.Errorf("expected an error, got %s", id)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if id != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, id)
+			}
+		})
+	}
+}
+
+func resolvedTeamID(client Client) func(value string) (string, error) {
+	return func(value string) (string, error) {
+		team, err := ResolveTeam(context.Background(), client, value)
+		if err != nil {
+			return "", err
+		}
+
+		return team.ID, nil
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/gonvenience/bunt"
+)
+
+const (
+	// maxRetries is the number of times a failed request is repeated
+	maxRetries = 5
+
+	// baseRetryDelay is the delay before the first retry, it doubles with
+	// every further retry up to maxRetryDelay
+	baseRetryDelay = 500 * time.Millisecond
+	maxRetryDelay  = 30 * time.Second
+
+	// maxRetryAfter bounds the wait time requested by the server
+	maxRetryAfter = 2 * time.Minute
+
+	// maxConcurrentRequests is the number of requests that may be in flight
+	// at the same time across all goroutines
+	maxConcurrentRequests = 6
+)
+
+// verboseOutput is the writer used to report retries, nil disables it
+var verboseOutput io.Writer
+
+// SetVerboseOutput sets the writer used to report retried requests, use nil
+// to stop reporting them
+func SetVerboseOutput(w io.Writer) {
+	verboseOutput = w
+}
+
+// retryClient is an HTTP client that limits the number of concurrent
+// requests and retries requests that were rate limited or failed with a
+// transient server or network error using jittered exponential backoff
+type retryClient struct {
+	client pagerduty.HTTPClient
+	budget chan struct{}
+}
+
+func newRetryClient(client pagerduty.HTTPClient) *retryClient {
+	return &retryClient{
+		client: client,
+		budget: make(chan struct{}, maxConcurrentRequests),
+	}
+}
+
+// Do sends the request and repeats it until it succeeds, the retries are
+// used up, or the context of the request is cancelled
+func (c *retryClient) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(req)
+
+		var reason string
+		switch {
+		case err != nil:
+			if ctx.Err() != nil || !idempotent(req.Method) {
+				return resp, err
+			}
+			reason = err.Error()
+
+		case resp.StatusCode == http.StatusTooManyRequests:
+			reason = resp.Status
+
+		case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented && idempotent(req.Method):
+			reason = resp.Status
+
+		default:
+			return resp, nil
+		}
+
+		if attempt == maxRetries || !replayable(req) {
+			return resp, err
+		}
+
+		delay := backoff(attempt)
+		if resp != nil {
+			if wait, ok := retryAfter(resp); ok {
+				delay = wait
+			}
+
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+
+		if verboseOutput != nil {
+			bunt.Fprintf(verboseOutput, "DimGray{Retrying %s %s in %v after %s (retry %d of %d)}\n",
+				req.Method, req.URL.Path, delay.Round(time.Millisecond), reason, attempt+1, maxRetries)
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+
+		if req, err = rewind(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send performs one attempt within the concurrency budget
+func (c *retryClient) send(req *http.Request) (*http.Response, error) {
+	select {
+	case c.budget <- struct{}{}:
+		defer func() { <-c.budget }()
+
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	return c.client.Do(req)
+}
+
+// idempotent returns whether a request with the method can be repeated
+// safely after it possibly reached the server
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// replayable returns whether the request body can be sent again
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewind returns a copy of the request with a fresh body
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, nil
+}
+
+// backoff returns the delay before the given retry, which is half of the
+// exponential delay plus a random share of the other half
+func backoff(attempt int) time.Duration {
+	delay := baseRetryDelay << attempt
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// retryAfter returns the wait time requested by the server using either the
+// Retry-After header (seconds or HTTP date) or the ratelimit-reset header
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	var wait time.Duration
+
+	if value := resp.Header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			wait = time.Duration(seconds) * time.Second
+
+		} else if date, err := http.ParseTime(value); err == nil {
+			wait = time.Until(date)
+
+		} else {
+			return 0, false
+		}
+
+	} else if value := resp.Header.Get("ratelimit-reset"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, false
+		}
+
+		wait = time.Duration(seconds) * time.Second
+
+	} else {
+		return 0, false
+	}
+
+	switch {
+	case wait < 0:
+		wait = 0
+
+	case wait > maxRetryAfter:
+		wait = maxRetryAfter
+	}
+
+	return wait, true
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
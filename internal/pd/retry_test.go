@@ -0,0 +1,339 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// statusServer answers the requests with the given status codes in order,
+// the last one is repeated, Retry-After is set to zero seconds if not given
+func statusServer(t *testing.T, header http.Header, codes ...int) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1))
+		if n > len(codes) {
+			n = len(codes)
+		}
+
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+
+		if w.Header().Get("Retry-After") == "" && w.Header().Get("ratelimit-reset") == "" {
+			w.Header().Set("Retry-After", "0")
+		}
+
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(codes[n-1])
+	}))
+
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestRetryClientDo(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		body     func() io.Reader
+		header   http.Header
+		codes    []int
+		status   int
+		requests int32
+		minDelay time.Duration
+	}{
+		{
+			name:     "rate limited request with Retry-After",
+			method:   http.MethodGet,
+			header:   http.Header{"Retry-After": []string{"1"}},
+			codes:    []int{http.StatusTooManyRequests, http.StatusOK},
+			status:   http.StatusOK,
+			requests: 2,
+			minDelay: time.Second,
+		},
+		{
+			name:     "server error on GET",
+			method:   http.MethodGet,
+			codes:    []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			status:   http.StatusOK,
+			requests: 3,
+		},
+		{
+			name:     "not implemented on GET",
+			method:   http.MethodGet,
+			codes:    []int{http.StatusNotImplemented, http.StatusOK},
+			status:   http.StatusNotImplemented,
+			requests: 1,
+		},
+		{
+			name:     "server error on POST",
+			method:   http.MethodPost,
+			body:     func() io.Reader { return strings.NewReader("{}") },
+			codes:    []int{http.StatusInternalServerError, http.StatusOK},
+			status:   http.StatusInternalServerError,
+			requests: 1,
+		},
+		{
+			name:     "rate limited POST with a replayable body",
+			method:   http.MethodPost,
+			body:     func() io.Reader { return strings.NewReader("{}") },
+			codes:    []int{http.StatusTooManyRequests, http.StatusCreated},
+			status:   http.StatusCreated,
+			requests: 2,
+		},
+		{
+			name:     "rate limited POST with a body that cannot be replayed",
+			method:   http.MethodPost,
+			body:     func() io.Reader { return io.NopCloser(strings.NewReader("{}")) },
+			codes:    []int{http.StatusTooManyRequests, http.StatusCreated},
+			status:   http.StatusTooManyRequests,
+			requests: 1,
+		},
+		{
+			name:     "retries are used up",
+			method:   http.MethodGet,
+			codes:    []int{http.StatusServiceUnavailable},
+			status:   http.StatusServiceUnavailable,
+			requests: maxRetries + 1,
+		},
+		{
+			name:     "client error",
+			method:   http.MethodGet,
+			codes:    []int{http.StatusNotFound, http.StatusOK},
+			status:   http.StatusNotFound,
+			requests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := statusServer(t, tt.header, tt.codes...)
+
+			var body io.Reader
+			if tt.body != nil {
+				body = tt.body()
+			}
+
+			req, err := http.NewRequest(tt.method, server.URL+"/incidents", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			start := time.Now()
+			resp, err := newRetryClient(server.Client()).Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+
+			if n := atomic.LoadInt32(requests); n != tt.requests {
+				t.Errorf("expected %d requests, got %d", tt.requests, n)
+			}
+
+			if elapsed := time.Since(start); elapsed < tt.minDelay {
+				t.Errorf("expected the retry to wait at least %v, it took %v", tt.minDelay, elapsed)
+			}
+		})
+	}
+}
+
+func TestRetryClientReportsRetries(t *testing.T) {
+	var out bytes.Buffer
+	SetVerboseOutput(&out)
+	t.Cleanup(func() { SetVerboseOutput(nil) })
+
+	server, _ := statusServer(t, nil, http.StatusServiceUnavailable, http.StatusOK)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/incidents", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := newRetryClient(server.Client()).Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if expected := "Retrying GET /incidents in 0s after 503 Service Unavailable (retry 1 of 5)\n"; out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestRetryClientStopsWhenCancelled(t *testing.T) {
+	server, requests := statusServer(t, http.Header{"Retry-After": []string{"60"}}, http.StatusServiceUnavailable)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/incidents", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if _, err := newRetryClient(server.Client()).Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the backoff to stop with the context, it took %v", elapsed)
+	}
+
+	if n := atomic.LoadInt32(requests); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestRetryClientLimitsConcurrentRequests(t *testing.T) {
+	var (
+		inFlight, peak int32
+		release        = make(chan struct{})
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			old := atomic.LoadInt32(&peak)
+			if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+
+		<-release
+	}))
+	defer server.Close()
+
+	client := newRetryClient(server.Client())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3*maxConcurrentRequests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+
+	// wait until the budget is used up and give further requests a chance
+	// to exceed it before all requests are answered
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&inFlight) < maxConcurrentRequests && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&peak); n != maxConcurrentRequests {
+		t.Errorf("expected at most %d concurrent requests, got %d", maxConcurrentRequests, n)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   http.Header
+		expected time.Duration
+		ok       bool
+	}{
+		{name: "seconds", header: http.Header{"Retry-After": []string{"3"}}, expected: 3 * time.Second, ok: true},
+		{name: "date in the past", header: http.Header{"Retry-After": []string{"Mon, 07 Nov 2022 08:00:00 GMT"}}, expected: 0, ok: true},
+		{name: "rate limit reset", header: http.Header{"Ratelimit-Reset": []string{"7"}}, expected: 7 * time.Second, ok: true},
+		{name: "Retry-After before rate limit reset", header: http.Header{"Retry-After": []string{"1"}, "Ratelimit-Reset": []string{"7"}}, expected: time.Second, ok: true},
+		{name: "bounded wait time", header: http.Header{"Retry-After": []string{"3600"}}, expected: maxRetryAfter, ok: true},
+		{name: "invalid value", header: http.Header{"Retry-After": []string{"soon"}}},
+		{name: "invalid rate limit reset", header: http.Header{"Ratelimit-Reset": []string{"soon"}}},
+		{name: "no header", header: http.Header{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, ok := retryAfter(&http.Response{Header: tt.header})
+			if ok != tt.ok || wait != tt.expected {
+				t.Errorf("expected %v (%v), got %v (%v)", tt.expected, tt.ok, wait, ok)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 0; attempt <= 64; attempt++ {
+		limit := maxRetryDelay
+		if attempt < 6 {
+			limit = baseRetryDelay << attempt
+		}
+
+		if delay := backoff(attempt); delay < limit/2 || delay > limit {
+			t.Errorf("expected delay of retry %d between %v and %v, got %v", attempt+1, limit/2, limit, delay)
+		}
+	}
+}
+
+func TestIdempotent(t *testing.T) {
+	tests := map[string]bool{
+		http.MethodGet:     true,
+		http.MethodHead:    true,
+		http.MethodOptions: true,
+		http.MethodPut:     true,
+		http.MethodDelete:  true,
+		http.MethodPost:    false,
+		http.MethodPatch:   false,
+	}
+
+	for method, expected := range tests {
+		if idempotent(method) != expected {
+			t.Errorf("expected idempotent(%s) to be %v", method, expected)
+		}
+	}
+}
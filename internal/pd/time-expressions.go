@@ -0,0 +1,146 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationPattern matches relative time expressions like 24h, 7d, or +2w
+var durationPattern = regexp.MustCompile(`^([+-]?)(\d+)(m|h|d|w)$`)
+
+// ParseTimeExpression returns the time range described by the expression,
+// relative expressions are based on now and calendar days are days in the
+// configured time zone:
+//
+//   - RFC3339 times and durations describe an instant (start equals end), a
+//     duration like 30m, 24h, 7d, or 2w is the instant that long ago, with a
+//     leading + it is in the future
+//   - dates (YYYY-MM-DD), today, yesterday, and tomorrow describe whole days
+//   - this-week, last-week, and next-week describe weeks starting on Monday
+//   - last-shift describes the most recent completed occurrence of the own shift
+func ParseTimeExpression(expression string, now time.Time) (TimeRange, error) {
+	expression = strings.TrimSpace(expression)
+
+	if t, err := time.Parse(time.RFC3339, expression); err == nil {
+		return TimeRange{t, t}, nil
+	}
+
+	expression = strings.ToLower(expression)
+
+	if t, err := time.ParseInLocation(dateLayout, expression, timezone); err == nil {
+		return days(t, 1), nil
+	}
+
+	if match := durationPattern.FindStringSubmatch(expression); match != nil {
+		amount, err := strconv.Atoi(match[2])
+		if err != nil {
+			return TimeRange{}, err
+		}
+
+		if match[1] != "+" {
+			amount = -amount
+		}
+
+		var t time.Time
+		switch match[3] {
+		case "m":
+			t = now.Add(time.Duration(amount) * time.Minute)
+
+		case "h":
+			t = now.Add(time.Duration(amount) * time.Hour)
+
+		case "d":
+			t = now.AddDate(0, 0, amount)
+
+		case "w":
+			t = now.AddDate(0, 0, 7*amount)
+		}
+
+		return TimeRange{t, t}, nil
+	}
+
+	local := now.In(timezone)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, timezone)
+	monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+
+	switch expression {
+	case "now":
+		return TimeRange{now, now}, nil
+
+	case "today":
+		return days(today, 1), nil
+
+	case "yesterday":
+		return days(today.AddDate(0, 0, -1), 1), nil
+
+	case "tomorrow":
+		return days(today.AddDate(0, 0, 1), 1), nil
+
+	case "this-week":
+		return days(monday, 7), nil
+
+	case "last-week":
+		return days(monday.AddDate(0, 0, -7), 7), nil
+
+	case "next-week":
+		return days(monday.AddDate(0, 0, 7), 7), nil
+
+	case "last-shift":
+		return lastOwnShift(now)
+	}
+
+	return TimeRange{}, fmt.Errorf("%q is not a supported time, use a date (YYYY-MM-DD), an RFC3339 time, a duration like 24h or 7d, or one of now, today, yesterday, tomorrow, this-week, last-week, next-week, last-shift", expression)
+}
+
+// days returns the range of the given number of calendar days starting with
+// the day of t, which is computed on calendar dates to handle DST changes
+func days(t time.Time, n int) TimeRange {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return TimeRange{start, start.AddDate(0, 0, n)}
+}
+
+// lastOwnShift returns the most recent occurrence of the own shift that ended
+// before now
+func lastOwnShift(now time.Time) (TimeRange, error) {
+	shifts, ownShiftName, err := LoadShifts()
+	if err != nil {
+		return TimeRange{}, err
+	}
+
+	for _, shift := range shifts {
+		if shift.Name != ownShiftName {
+			continue
+		}
+
+		if timeRange, ok := shift.LastOccurrence(now); ok {
+			return timeRange, nil
+		}
+
+		return TimeRange{}, fmt.Errorf("shift %s was not in charge within the last %d days", shift.Name, searchHorizonDays)
+	}
+
+	return TimeRange{}, fmt.Errorf("last-shift requires the own-shift in the .pd.yml file to be set, see 'pd set-own-shift'")
+}
@@ -0,0 +1,76 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import "testing"
+
+func TestParseTimeExpression(t *testing.T) {
+	if err := SetTimezone("Europe/Berlin"); err != nil {
+		t.Fatal(err)
+	}
+	defer SetTimezone("")
+
+	// Wednesday, the day of the switch to summer time is the Sunday after
+	now := mustParseTime(t, "2022-03-23T10:30:00Z")
+
+	tests := []struct {
+		expression string
+		start      string
+		end        string
+		fails      bool
+	}{
+		{expression: "now", start: "2022-03-23T10:30:00Z", end: "2022-03-23T10:30:00Z"},
+		{expression: "2022-11-07T08:00:00+01:00", start: "2022-11-07T07:00:00Z", end: "2022-11-07T07:00:00Z"},
+		{expression: "24h", start: "2022-03-22T10:30:00Z", end: "2022-03-22T10:30:00Z"},
+		{expression: "+30m", start: "2022-03-23T11:00:00Z", end: "2022-03-23T11:00:00Z"},
+		{expression: "7d", start: "2022-03-16T10:30:00Z", end: "2022-03-16T10:30:00Z"},
+		{expression: "-2w", start: "2022-03-09T10:30:00Z", end: "2022-03-09T10:30:00Z"},
+		{expression: "today", start: "2022-03-22T23:00:00Z", end: "2022-03-23T23:00:00Z"},
+		{expression: " Yesterday ", start: "2022-03-21T23:00:00Z", end: "2022-03-22T23:00:00Z"},
+		{expression: "tomorrow", start: "2022-03-23T23:00:00Z", end: "2022-03-24T23:00:00Z"},
+		{expression: "2022-03-27", start: "2022-03-26T23:00:00Z", end: "2022-03-27T22:00:00Z"},
+		{expression: "this-week", start: "2022-03-20T23:00:00Z", end: "2022-03-27T22:00:00Z"},
+		{expression: "last-week", start: "2022-03-13T23:00:00Z", end: "2022-03-20T23:00:00Z"},
+		{expression: "next-week", start: "2022-03-27T22:00:00Z", end: "2022-04-03T22:00:00Z"},
+		{expression: "3y", fails: true},
+		{expression: "next-month", fails: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			timeRange, err := ParseTimeExpression(tt.expression, now)
+			if tt.fails {
+				if err == nil {
+					t.Errorf("expected an error, got %v", timeRange)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !timeRange.Start.Equal(mustParseTime(t, tt.start)) || !timeRange.End.Equal(mustParseTime(t, tt.end)) {
+				t.Errorf("expected %s to %s, got %s to %s", tt.start, tt.end, timeRange.Start.UTC(), timeRange.End.UTC())
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timestampLayouts are the timestamp formats found in PagerDuty API responses,
+// timestamps without offset are in UTC
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// timezone is the time zone used to display times and to interpret dates
+var timezone = time.Local
+
+// ParseTimestamp parses a timestamp as returned by the PagerDuty API, with
+// or without fractional seconds, and with a Z, ±hh:mm, ±hhmm, or no offset
+func ParseTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%q is not a supported timestamp", value)
+}
+
+// FormatTimestamp returns the PagerDuty timestamp in the configured time
+// zone using the layout, timestamps that cannot be parsed are returned as-is
+func FormatTimestamp(value string, layout string) string {
+	t, err := ParseTimestamp(value)
+	if err != nil {
+		return value
+	}
+
+	return InTimezone(t).Format(layout)
+}
+
+// SetTimezone sets the time zone used to display times and to interpret
+// dates, it takes an IANA time zone name, an empty name or "local" select
+// the time zone of the system
+func SetTimezone(name string) error {
+	if name == "" || strings.EqualFold(name, "local") {
+		timezone = time.Local
+		return nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("unknown time zone %q, use an IANA time zone name like Europe/Berlin", name)
+	}
+
+	timezone = loc
+	return nil
+}
+
+// Timezone returns the time zone used to display times and to interpret dates
+func Timezone() *time.Location {
+	return timezone
+}
+
+// InTimezone returns the instant in the configured time zone
+func InTimezone(t time.Time) time.Time {
+	return t.In(timezone)
+}
@@ -0,0 +1,88 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+		fails    bool
+	}{
+		{value: "2022-11-07T08:00:00Z", expected: "2022-11-07T08:00:00Z"},
+		{value: "2022-11-07T08:00:00.123Z", expected: "2022-11-07T08:00:00.123Z"},
+		{value: "2022-11-07T09:00:00+01:00", expected: "2022-11-07T08:00:00Z"},
+		{value: "2022-11-07T09:00:00+0100", expected: "2022-11-07T08:00:00Z"},
+		{value: "2022-11-07T08:00:00", expected: "2022-11-07T08:00:00Z"},
+		{value: "2022-11-07 03:00:00 -0500", expected: "2022-11-07T08:00:00Z"},
+		{value: " 2022-11-07 08:00:00 ", expected: "2022-11-07T08:00:00Z"},
+		{value: "", fails: true},
+		{value: "yesterday", fails: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			result, err := ParseTimestamp(tt.value)
+			if tt.fails {
+				if err == nil {
+					t.Errorf("expected an error, got %s", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected, err := time.Parse(time.RFC3339Nano, tt.expected)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !result.Equal(expected) {
+				t.Errorf("expected %s, got %s", expected, result)
+			}
+		})
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	if err := SetTimezone("America/New_York"); err != nil {
+		t.Fatal(err)
+	}
+	defer SetTimezone("")
+
+	if result := FormatTimestamp("2022-11-07T13:00:00.5+00:00", "2006-01-02 15:04"); result != "2022-11-07 08:00" {
+		t.Errorf("expected the timestamp in the configured time zone, got %s", result)
+	}
+
+	if result := FormatTimestamp("soon", "2006-01-02 15:04"); result != "soon" {
+		t.Errorf("expected invalid timestamps to be returned as-is, got %s", result)
+	}
+
+	if err := SetTimezone("Mars/Olympus_Mons"); err == nil {
+		t.Errorf("expected an error for an unknown time zone")
+	}
+}
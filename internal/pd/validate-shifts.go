@@ -0,0 +1,331 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ShiftIssue describes a problem of the shift configuration, warnings do not
+// prevent the shifts from being used
+type ShiftIssue struct {
+	Line    int
+	Message string
+	Warning bool
+}
+
+func (i ShiftIssue) Error() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("line %d: %s", i.Line, i.Message)
+	}
+
+	return i.Message
+}
+
+// ValidateShifts checks the shifts of the selected profile for malformed
+// entries, duplicate names, overlaps, and gaps in the rotation
+func ValidateShifts() ([]ShiftIssue, error) {
+	profile, err := loadProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	_, issues := validateShifts(profile.ShiftTimes)
+	return issues, nil
+}
+
+// validateShifts parses and checks the configured shifts, the issues are
+// sorted by line
+func validateShifts(configs []ShiftConfig) ([]Shift, []ShiftIssue) {
+	shifts, issues := parseShifts(configs)
+	issues = append(issues, checkShifts(shifts)...)
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Line < issues[j].Line
+	})
+
+	return shifts, issues
+}
+
+// parseShifts converts the configured shift entries, malformed entries are
+// reported and skipped
+func parseShifts(configs []ShiftConfig) ([]Shift, []ShiftIssue) {
+	var (
+		shifts []Shift
+		issues []ShiftIssue
+		names  = map[string]int{}
+	)
+
+	for _, config := range configs {
+		reported := len(issues)
+		report := func(format string, a ...interface{}) {
+			issues = append(issues, ShiftIssue{Line: config.Line, Message: fmt.Sprintf(format, a...)})
+		}
+
+		if config.Name == "" {
+			report("shift has no name")
+		} else if line, found := names[config.Name]; found {
+			report("shift name %q is already used in line %d", config.Name, line)
+		} else {
+			names[config.Name] = config.Line
+		}
+
+		start, err := parseShiftTime(config.Start)
+		if err != nil {
+			report("invalid start of shift %q: %v", config.Name, err)
+		}
+
+		end, err := parseShiftTime(config.End)
+		if err != nil {
+			report("invalid end of shift %q: %v", config.Name, err)
+		}
+
+		location := time.UTC
+		if config.TZ != "" {
+			if location, err = time.LoadLocation(config.TZ); err != nil {
+				report("invalid time zone of shift %q: %v", config.Name, err)
+			}
+		}
+
+		weekdays, err := parseWeekdays(config.Days)
+		if err != nil {
+			report("invalid days of shift %q: %v", config.Name, err)
+		}
+
+		for _, date := range config.Dates {
+			if _, err := time.Parse(dateLayout, date); err != nil {
+				report("invalid date %q of shift %q, expected format is YYYY-MM-DD", date, config.Name)
+			}
+		}
+
+		if len(issues) > reported {
+			continue
+		}
+
+		shifts = append(shifts, Shift{
+			Start:    start,
+			End:      end,
+			Name:     config.Name,
+			Location: location,
+			Weekdays: weekdays,
+			Dates:    config.Dates,
+			line:     config.Line,
+		})
+	}
+
+	return shifts, issues
+}
+
+// parseShiftTime parses a time of the day in the format H:MM or HH:MM, 24:00
+// is accepted to denote the end of the day
+func parseShiftTime(str string) (ShiftTime, error) {
+	hh, mm, found := strings.Cut(strings.TrimSpace(str), ":")
+	if !found || len(hh) < 1 || len(hh) > 2 || len(mm) != 2 {
+		return 0, fmt.Errorf("%q is not in the format HH:MM", str)
+	}
+
+	hours, err := strconv.Atoi(hh)
+	if err != nil || hours < 0 || hours > 24 {
+		return 0, fmt.Errorf("%q has an invalid hour", str)
+	}
+
+	mins, err := strconv.Atoi(mm)
+	if err != nil || mins < 0 || mins > 59 || (hours == 24 && mins != 0) {
+		return 0, fmt.Errorf("%q has invalid minutes", str)
+	}
+
+	return ShiftTime(hours*60 + mins), nil
+}
+
+// checkShifts reports overlaps of shifts with the same precedence as errors,
+// and times of the upcoming week that are not covered by any shift as warnings
+func checkShifts(shifts []Shift) []ShiftIssue {
+	if len(shifts) == 0 {
+		return nil
+	}
+
+	var (
+		issues    []ShiftIssue
+		now       = time.Now().UTC()
+		window    = TimeRange{Start: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)}
+		reference = shifts[0].location()
+	)
+	window.End = window.Start.AddDate(0, 0, 7)
+
+	occurrences := make([][]TimeRange, len(shifts))
+	for i, shift := range shifts {
+		occurrences[i] = shift.occurrencesAround(window)
+	}
+
+	for i := range shifts {
+		for j := i + 1; j < len(shifts); j++ {
+			if shifts[i].precedence() != shifts[j].precedence() {
+				continue
+			}
+
+			if overlap, found := firstOverlap(occurrences[i], occurrences[j]); found {
+				issues = append(issues, ShiftIssue{
+					Line: shifts[j].line,
+					Message: fmt.Sprintf("shift %q overlaps with shift %q (line %d) from %s to %s",
+						shifts[j].Name, shifts[i].Name, shifts[i].line,
+						overlap.Start.In(reference).Format("Mon 2006-01-02 15:04 MST"),
+						overlap.End.In(reference).Format("Mon 2006-01-02 15:04 MST"),
+					),
+				})
+			}
+		}
+	}
+
+	var covered []TimeRange
+	for i, shift := range shifts {
+		if len(shift.Dates) == 0 {
+			covered = append(covered, occurrences[i]...)
+		}
+	}
+
+	type clockRange struct{ start, end string }
+	var (
+		gaps     []clockRange
+		gapDays  = map[clockRange][]string{}
+		timeZone = reference.String()
+	)
+
+	for _, gap := range uncovered(window, covered) {
+		key := clockRange{gap.Start.In(reference).Format("15:04"), gap.End.In(reference).Format("15:04")}
+		if _, found := gapDays[key]; !found {
+			gaps = append(gaps, key)
+		}
+
+		gapDays[key] = append(gapDays[key], gap.Start.In(reference).Format("Mon"))
+	}
+
+	for _, gap := range gaps {
+		days := "on " + strings.Join(gapDays[gap], ", ")
+		if len(gapDays[gap]) >= 7 {
+			days = "every day"
+		}
+
+		issues = append(issues, ShiftIssue{
+			Message: fmt.Sprintf("no shift covers %s to %s (%s) %s", gap.start, gap.end, timeZone, days),
+			Warning: true,
+		})
+	}
+
+	return issues
+}
+
+// occurrencesAround returns all occurrences of the shift that may intersect
+// with the given time range, or all occurrences of a date specific shift
+func (s Shift) occurrencesAround(window TimeRange) []TimeRange {
+	var result []TimeRange
+	if len(s.Dates) > 0 {
+		for _, date := range s.Dates {
+			if d, err := time.Parse(dateLayout, date); err == nil {
+				result = append(result, s.On(d.Year(), d.Month(), d.Day()))
+			}
+		}
+
+		return result
+	}
+
+	local := window.Start.In(s.location())
+	days := int(window.End.Sub(window.Start).Hours()/24) + 1
+	for offset := -1; offset <= days; offset++ {
+		year, month, day := local.Year(), local.Month(), local.Day()+offset
+		if s.StartsOn(year, month, day) {
+			result = append(result, s.On(year, month, day))
+		}
+	}
+
+	return result
+}
+
+func firstOverlap(a []TimeRange, b []TimeRange) (TimeRange, bool) {
+	for _, x := range a {
+		for _, y := range b {
+			if x.Start.Before(y.End) && y.Start.Before(x.End) {
+				return TimeRange{Start: latest(x.Start, y.Start), End: earliest(x.End, y.End)}, true
+			}
+		}
+	}
+
+	return TimeRange{}, false
+}
+
+// uncovered returns the parts of the window that are not covered by any of the ranges
+func uncovered(window TimeRange, ranges []TimeRange) []TimeRange {
+	sorted := make([]TimeRange, len(ranges))
+	copy(sorted, ranges)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	var (
+		gaps   []TimeRange
+		cursor = window.Start
+	)
+
+	for _, r := range sorted {
+		if !r.End.After(cursor) {
+			continue
+		}
+
+		if r.Start.After(cursor) {
+			gaps = append(gaps, TimeRange{Start: cursor, End: earliest(r.Start, window.End)})
+		}
+
+		cursor = r.End
+		if !cursor.Before(window.End) {
+			break
+		}
+	}
+
+	if cursor.Before(window.End) {
+		gaps = append(gaps, TimeRange{Start: cursor, End: window.End})
+	}
+
+	var result []TimeRange
+	for _, gap := range gaps {
+		if gap.Start.Before(gap.End) && gap.Start.Before(window.End) {
+			result = append(result, gap)
+		}
+	}
+
+	return result
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
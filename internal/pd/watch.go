@@ -0,0 +1,137 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+// watchedLogEntryTypes are the log entry types reported as incident changes
+var watchedLogEntryTypes = map[string]bool{
+	"trigger_log_entry":     true,
+	"acknowledge_log_entry": true,
+	"escalate_log_entry":    true,
+	"assign_log_entry":      true,
+	"resolve_log_entry":     true,
+}
+
+// IncidentChange is a trigger, acknowledgement, escalation, reassignment, or
+// resolution of an incident
+type IncidentChange struct {
+	TimelineEvent
+	Incident pagerduty.APIObject
+}
+
+// IncidentWatcher reports the changes of the incidents of teams by polling
+// the log entries that were created since the previous poll
+type IncidentWatcher struct {
+	client  Client
+	teamIDs []string
+	since   time.Time
+	seen    map[string]time.Time
+}
+
+// NewIncidentWatcher creates a watcher for the incidents of the teams, the
+// first poll reports the changes after the since time
+func NewIncidentWatcher(client Client, teamIDs []string, since time.Time) *IncidentWatcher {
+	return &IncidentWatcher{
+		client:  client,
+		teamIDs: teamIDs,
+		since:   since,
+		seen:    map[string]time.Time{},
+	}
+}
+
+// Poll returns the incident changes since the previous poll in chronological
+// order, since the time filter of the API includes the start time, the log
+// entries at the start time are remembered to not report them twice
+func (w *IncidentWatcher) Poll(ctx context.Context) ([]IncidentChange, error) {
+	logEntries, err := ListAllLogEntries(ctx, w.client, pagerduty.ListLogEntriesOptions{
+		Since:      w.since.UTC().Format(time.RFC3339),
+		TeamIDs:    w.teamIDs,
+		IsOverview: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the API returns the newest log entries first, so the start of the next
+	// poll is only moved after all entries were checked against this one
+	var (
+		since   = w.since
+		latest  = w.since
+		changes []IncidentChange
+	)
+
+	for _, logEntry := range logEntries {
+		if _, seen := w.seen[logEntry.ID]; seen {
+			continue
+		}
+
+		createdAt, err := ParseTimestamp(logEntry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if createdAt.Before(since) {
+			continue
+		}
+
+		w.seen[logEntry.ID] = createdAt
+		if createdAt.After(latest) {
+			latest = createdAt
+		}
+
+		if !watchedLogEntryTypes[logEntry.Type] {
+			continue
+		}
+
+		changes = append(changes, IncidentChange{
+			TimelineEvent: TimelineEvent{
+				Time:    createdAt,
+				Type:    strings.TrimSuffix(logEntry.Type, "_log_entry"),
+				Actor:   logEntryActor(logEntry),
+				Summary: logEntrySummary(logEntry),
+			},
+			Incident: logEntry.Incident.APIObject,
+		})
+	}
+
+	w.since = latest
+
+	// only the log entries at the start of the next poll can be returned again
+	for id, createdAt := range w.seen {
+		if createdAt.Before(w.since) {
+			delete(w.seen, id)
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Time.Before(changes[j].Time)
+	})
+
+	return changes, nil
+}
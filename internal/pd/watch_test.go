@@ -0,0 +1,134 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+func fakeLogEntry(id string, kind string, createdAt string) pagerduty.LogEntry {
+	return pagerduty.LogEntry{CommonLogEntryField: pagerduty.CommonLogEntryField{
+		APIObject: pagerduty.APIObject{ID: id, Type: kind},
+		CreatedAt: createdAt,
+	}}
+}
+
+func TestIncidentWatcherPoll(t *testing.T) {
+	// log entries are stored newest first, like the PagerDuty API returns them
+	tests := []struct {
+		name       string
+		logEntries map[string][]pagerduty.LogEntry
+		expected   []string
+	}{
+		{
+			name: "trigger and resolve of one incident",
+			logEntries: map[string][]pagerduty.LogEntry{
+				"PINC001": {
+					fakeLogEntry("L2", "resolve_log_entry", "2022-11-07T08:10:00Z"),
+					fakeLogEntry("L1", "trigger_log_entry", "2022-11-07T08:00:00Z"),
+				},
+			},
+			expected: []string{"PINC001 trigger", "PINC001 resolve"},
+		},
+		{
+			name: "changes of several incidents",
+			logEntries: map[string][]pagerduty.LogEntry{
+				"PINC001": {
+					fakeLogEntry("L3", "acknowledge_log_entry", "2022-11-07T08:20:00Z"),
+					fakeLogEntry("L1", "trigger_log_entry", "2022-11-07T08:00:00Z"),
+				},
+				"PINC002": {
+					fakeLogEntry("L4", "notify_log_entry", "2022-11-07T08:15:00Z"),
+					fakeLogEntry("L2", "trigger_log_entry", "2022-11-07T08:10:00Z"),
+				},
+			},
+			expected: []string{"PINC001 trigger", "PINC002 trigger", "PINC001 acknowledge"},
+		},
+		{
+			name: "changes before the start are left out",
+			logEntries: map[string][]pagerduty.LogEntry{
+				"PINC001": {
+					fakeLogEntry("L2", "resolve_log_entry", "2022-11-07T08:10:00Z"),
+					fakeLogEntry("L1", "trigger_log_entry", "2022-11-07T07:00:00Z"),
+				},
+			},
+			expected: []string{"PINC001 resolve"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &FakeClient{LogEntries: tt.logEntries}
+			watcher := NewIncidentWatcher(client, nil, mustParseTime(t, "2022-11-07T08:00:00Z"))
+
+			changes, err := watcher.Poll(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var result []string
+			for _, change := range changes {
+				result = append(result, change.Incident.ID+" "+change.Type)
+			}
+
+			if !equalStrings(result, tt.expected) {
+				t.Errorf("expected changes %v, got %v", tt.expected, result)
+			}
+
+			if changes, err := watcher.Poll(context.Background()); err != nil || len(changes) > 0 {
+				t.Errorf("expected no changes to be reported twice, got %v (%v)", changes, err)
+			}
+		})
+	}
+}
+
+func TestIncidentWatcherReportsLaterChanges(t *testing.T) {
+	client := &FakeClient{LogEntries: map[string][]pagerduty.LogEntry{
+		"PINC001": {fakeLogEntry("L1", "trigger_log_entry", "2022-11-07T08:00:00Z")},
+	}}
+
+	watcher := NewIncidentWatcher(client, nil, mustParseTime(t, "2022-11-07T07:00:00Z"))
+	if changes, err := watcher.Poll(context.Background()); err != nil || len(changes) != 1 {
+		t.Fatalf("expected the trigger to be reported, got %v (%v)", changes, err)
+	}
+
+	client.LogEntries["PINC001"] = append([]pagerduty.LogEntry{
+		fakeLogEntry("L3", "resolve_log_entry", "2022-11-07T08:30:00Z"),
+		fakeLogEntry("L2", "acknowledge_log_entry", "2022-11-07T08:00:00Z"),
+	}, client.LogEntries["PINC001"]...)
+
+	changes, err := watcher.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result []string
+	for _, change := range changes {
+		result = append(result, change.Type)
+	}
+
+	if expected := []string{"acknowledge", "resolve"}; !equalStrings(result, expected) {
+		t.Errorf("expected changes %v, got %v", expected, result)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultParallelism is the number of workers used for parallel API requests
+const DefaultParallelism = 10
+
+// ParallelMap calls fn for all items using at most parallel workers and
+// returns the results in the order of the items. The first failing call
+// cancels the context of the remaining calls, all errors are returned in
+// the order of the items, except for those caused by the cancellation.
+func ParallelMap[T any, R any](ctx context.Context, parallel int, items []T, fn func(ctx context.Context, item T) (R, error)) ([]R, []error) {
+	if parallel <= 0 {
+		parallel = DefaultParallelism
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		results = make([]R, len(items))
+		errs    = make([]error, len(items))
+		tasks   = make(chan int)
+		wg      sync.WaitGroup
+		once    sync.Once
+		failed  = make(chan struct{})
+	)
+
+	wg.Add(parallel)
+	for i := 0; i < parallel; i++ {
+		go func() {
+			defer wg.Done()
+			for idx := range tasks {
+				result, err := fn(ctx, items[idx])
+				if err != nil {
+					// calls aborted because of an earlier failure or because the
+					// caller gave up are no news, this is decided on the context
+					// since the wrapped errors do not reveal their cause
+					if ctx.Err() != nil {
+						continue
+					}
+
+					errs[idx] = err
+					once.Do(func() {
+						close(failed)
+						cancel()
+					})
+					continue
+				}
+
+				results[idx] = result
+			}
+		}()
+	}
+
+	for idx := range items {
+		select {
+		case tasks <- idx:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	close(tasks)
+	wg.Wait()
+
+	var collected []error
+	for _, err := range errs {
+		if err != nil {
+			collected = append(collected, err)
+		}
+	}
+
+	if len(collected) == 0 && ctx.Err() != nil && !isClosed(failed) {
+		collected = append(collected, ctx.Err())
+	}
+
+	return results, collected
+}
+
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+
+	default:
+		return false
+	}
+}
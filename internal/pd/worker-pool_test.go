@@ -0,0 +1,236 @@
+// Copyright © 2020 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/gonvenience/wrap"
+)
+
+func fakeIncidents(n int) (*FakeClient, []string) {
+	client := &FakeClient{}
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("PINC%03d", i)
+		client.Incidents = append(client.Incidents, pagerduty.Incident{
+			APIObject: pagerduty.APIObject{ID: ids[i]},
+			Title:     "incident " + ids[i],
+		})
+	}
+
+	return client, ids
+}
+
+// getTitle looks up the title of the incident, it waits for the given delay
+// or until the context is cancelled, like a slow API request would
+func getTitle(client Client, delay func(id string) time.Duration) func(ctx context.Context, id string) (string, error) {
+	return func(ctx context.Context, id string) (string, error) {
+		select {
+		case <-ctx.Done():
+			return "", wrap.Errorf(ctx.Err(), "item %s", id)
+
+		case <-time.After(delay(id)):
+		}
+
+		incident, err := client.GetIncidentWithContext(ctx, id)
+		if err != nil {
+			return "", wrap.Errorf(err, "item %s", id)
+		}
+
+		return incident.Title, nil
+	}
+}
+
+func TestParallelMapKeepsOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    int
+		parallel int
+	}{
+		{name: "no items", items: 0, parallel: 3},
+		{name: "single worker", items: 10, parallel: 1},
+		{name: "fewer workers than items", items: 25, parallel: 4},
+		{name: "more workers than items", items: 5, parallel: 20},
+		{name: "default parallelism", items: 25, parallel: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, ids := fakeIncidents(tt.items)
+
+			// later items finish first to mix up the completion order
+			delay := func(id string) time.Duration {
+				var n int
+				fmt.Sscanf(id, "PINC%d", &n)
+				return time.Duration(tt.items-n) * time.Millisecond
+			}
+
+			results, errs := ParallelMap(context.Background(), tt.parallel, ids, getTitle(client, delay))
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			if len(results) != len(ids) {
+				t.Fatalf("expected %d results, got %d", len(ids), len(results))
+			}
+
+			for i, id := range ids {
+				if results[i] != "incident "+id {
+					t.Errorf("expected result %d to be the title of %s, got %q", i, id, results[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParallelMapFirstFailureCancelsRemainingWork(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    int
+		parallel int
+		failing  int
+	}{
+		{name: "first item fails", items: 30, parallel: 3, failing: 0},
+		{name: "later item fails", items: 30, parallel: 4, failing: 5},
+		{name: "single worker", items: 10, parallel: 1, failing: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, ids := fakeIncidents(tt.items)
+			ids[tt.failing] = "PUNKNOWN"
+
+			var started int32
+			fn := getTitle(client, func(id string) time.Duration {
+				atomic.AddInt32(&started, 1)
+				// the items up to the failing one finish right away, the
+				// others only finish if nobody cancels them
+				if id == "PUNKNOWN" || id < fmt.Sprintf("PINC%03d", tt.failing) {
+					return 0
+				}
+
+				return time.Minute
+			})
+
+			done := make(chan struct{})
+			var errs []error
+			go func() {
+				defer close(done)
+				_, errs = ParallelMap(context.Background(), tt.parallel, ids, fn)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(10 * time.Second):
+				t.Fatal("expected the failure to cancel the remaining requests")
+			}
+
+			if len(errs) != 1 {
+				t.Fatalf("expected exactly one error, got %d: %v", len(errs), errs)
+			}
+
+			if !strings.Contains(errs[0].Error(), "item PUNKNOWN") {
+				t.Errorf("expected the error of the failing item, got %v", errs[0])
+			}
+
+			if n := int(atomic.LoadInt32(&started)); n >= tt.items {
+				t.Errorf("expected the remaining items not to be started, but %d of %d were", n, tt.items)
+			}
+		})
+	}
+}
+
+func TestParallelMapCollectsErrorsPerItem(t *testing.T) {
+	client, ids := fakeIncidents(6)
+	failing := map[string]bool{}
+	for i := range ids {
+		if i%2 == 1 {
+			ids[i] = fmt.Sprintf("PGONE%03d", i)
+			failing[ids[i]] = true
+		}
+	}
+
+	// all requests are in flight before the first one fails
+	var arrived sync.WaitGroup
+	arrived.Add(len(ids))
+
+	results, errs := ParallelMap(context.Background(), len(ids), ids, func(ctx context.Context, id string) (string, error) {
+		arrived.Done()
+		arrived.Wait()
+
+		incident, err := client.GetIncidentWithContext(ctx, id)
+		if err != nil {
+			return "", wrap.Errorf(err, "item %s", id)
+		}
+
+		return incident.Title, nil
+	})
+
+	if len(errs) == 0 || len(errs) > len(failing) {
+		t.Fatalf("expected between one and %d errors, got %d: %v", len(failing), len(errs), errs)
+	}
+
+	var last = -1
+	for _, err := range errs {
+		pos := -1
+		for i, id := range ids {
+			if strings.Contains(err.Error(), "item "+id+":") {
+				pos = i
+			}
+		}
+
+		switch {
+		case pos == -1 || !failing[ids[pos]]:
+			t.Errorf("expected only errors of failing items, got %v", err)
+
+		case pos <= last:
+			t.Errorf("expected errors in the order of the items, got %v", errs)
+		}
+
+		last = pos
+	}
+
+	for i, id := range ids {
+		if !failing[id] && results[i] != "" && results[i] != "incident "+id {
+			t.Errorf("expected result %d to be the title of %s, got %q", i, id, results[i])
+		}
+	}
+}
+
+func TestParallelMapCancelledByCaller(t *testing.T) {
+	client, ids := fakeIncidents(20)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, errs := ParallelMap(ctx, 4, ids, getTitle(client, func(string) time.Duration { return time.Minute }))
+	if len(errs) != 1 || errs[0] != context.Canceled {
+		t.Errorf("expected only the cancellation to be reported, got %v", errs)
+	}
+}